@@ -0,0 +1,247 @@
+package blocktrace
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database"
+)
+
+const (
+	BlockReceiptsRPC = "eth_getBlockReceipts"
+	CallRPC          = "eth_call"
+	// selectors of the token0() and token1() functions of Uniswap pools
+	Token0Selector = "0x0dfe1681"
+	Token1Selector = "0xd21220a7"
+)
+
+// kinds of MEV detected by analyzing the receipts of a block, used as metric label
+const (
+	MEVKindSandwich     = "sandwich"
+	MEVKindArbitrage    = "arbitrage"
+	MEVKindLiquidation  = "liquidation"
+	MEVKindTokenPayment = "token_payment"
+	MEVKindBundle       = "bundle"
+)
+
+// poolTokens caches the tokens of DEX pools, which never change
+type poolTokens struct {
+	mu     sync.Mutex
+	tokens map[common.Address][2]common.Address
+}
+
+// blockReceipts fetches the receipts of all transactions of a block.
+// Errors are logged, and nil is returned, since the analysis is best effort.
+func (t *Tracer) blockReceipts(ctx context.Context, blockHash common.Hash) []*Receipt {
+	ctx, cancel := context.WithTimeout(ctx, t.CallTimeout)
+	defer cancel()
+	resp, err := t.call(ctx, BlockReceiptsRPC, blockHash)
+	if err != nil {
+		t.log.Error("failed rpc call", "endpoint", BlockReceiptsRPC, "error", err)
+		return nil
+	}
+	var receipts []*Receipt
+	if err := resp.GetObject(&receipts); err != nil {
+		t.recordRPCError(ctx, BlockReceiptsRPC)
+		t.log.Error("failed to get receipts from response", "endpoint", BlockReceiptsRPC, "error", err)
+		return nil
+	}
+	return receipts
+}
+
+// analyzeBlock detects MEV in the receipts of a block, and adds it to mevBlock and its coinbase transfers txs
+func (t *Tracer) analyzeBlock(
+	ctx context.Context,
+	receipts []*Receipt,
+	mevBlock *database.MEVBlock,
+	txs []*database.MEVTransaction,
+) {
+	if len(receipts) == 0 {
+		return
+	}
+	swaps := decodeSwaps(receipts)
+	t.analyzeSandwiches(ctx, swaps, mevBlock)
+	t.analyzeArbitrages(ctx, swaps, txs)
+	t.analyzeLiquidations(ctx, receipts, mevBlock, txs)
+	t.analyzeTokenPayments(ctx, receipts, mevBlock)
+	mevBlock.Bundles = detectBundles(mevBlock.BlockNumber, receipts, txs)
+	t.recordDetected(ctx, MEVKindBundle, len(mevBlock.Bundles))
+}
+
+// analyzeSandwiches adds the sandwiches found in swaps to mevBlock
+func (t *Tracer) analyzeSandwiches(ctx context.Context, swaps []*Swap, mevBlock *database.MEVBlock) {
+	for _, sw := range detectSandwiches(mevBlock.BlockNumber, swaps) {
+		tokens, err := t.poolTokens(ctx, sw.Pool)
+		if err != nil {
+			t.log.Warn("failed to get the tokens of a pool", "pool", sw.Pool, "error", err)
+		} else if sw.profitInToken0 {
+			sw.ProfitToken = tokens[0]
+		} else {
+			sw.ProfitToken = tokens[1]
+		}
+		mevBlock.Sandwiches = append(mevBlock.Sandwiches, sw.Sandwich)
+	}
+	t.recordDetected(ctx, MEVKindSandwich, len(mevBlock.Sandwiches))
+}
+
+// analyzeArbitrages looks for cyclic arbitrages in the txs paying the coinbase,
+// and attaches them to their coinbase transfers
+func (t *Tracer) analyzeArbitrages(ctx context.Context, swaps []*Swap, txs []*database.MEVTransaction) {
+	bribes, hashes := coinbaseBribes(txs)
+	swapsByTx := map[common.Hash][]*Swap{}
+	for _, s := range swaps {
+		if _, ok := bribes[s.TxHash]; ok {
+			swapsByTx[s.TxHash] = append(swapsByTx[s.TxHash], s)
+		}
+	}
+
+	detected := 0
+	for _, hash := range hashes {
+		if len(swapsByTx[hash]) < 2 {
+			continue
+		}
+		tokenSwaps := make([]*tokenSwap, 0, len(swapsByTx[hash]))
+		for _, s := range swapsByTx[hash] {
+			tokens, err := t.poolTokens(ctx, s.Pool)
+			if err != nil {
+				t.log.Warn("failed to get the tokens of a pool", "pool", s.Pool, "error", err)
+				break
+			}
+			tokenSwaps = append(tokenSwaps, newTokenSwap(s, tokens))
+		}
+		if len(tokenSwaps) < len(swapsByTx[hash]) {
+			continue
+		}
+		arb := detectArbitrage(tokenSwaps)
+		if arb == nil {
+			continue
+		}
+		setBribe(arb, bribes[hash])
+		for _, tx := range txs {
+			if tx.TXHash == hash {
+				tx.Arbitrage = arb
+			}
+		}
+		detected++
+	}
+	t.recordDetected(ctx, MEVKindArbitrage, detected)
+}
+
+// analyzeLiquidations adds the liquidations found in receipts to mevBlock,
+// with the coinbase payment of their tx if any
+func (t *Tracer) analyzeLiquidations(
+	ctx context.Context,
+	receipts []*Receipt,
+	mevBlock *database.MEVBlock,
+	txs []*database.MEVTransaction,
+) {
+	bribes, _ := coinbaseBribes(txs)
+	for _, liq := range decodeLiquidations(mevBlock.BlockNumber, receipts) {
+		liq.Bribe = bribes[liq.TxHash]
+		mevBlock.Liquidations = append(mevBlock.Liquidations, liq)
+	}
+	t.recordDetected(ctx, MEVKindLiquidation, len(mevBlock.Liquidations))
+}
+
+// analyzeTokenPayments adds the ERC-20 transfers to the miner found in receipts to mevBlock,
+// with the decimals of their token, and totals them per token
+func (t *Tracer) analyzeTokenPayments(ctx context.Context, receipts []*Receipt, mevBlock *database.MEVBlock) {
+	for _, p := range decodeTokenPayments(mevBlock.BlockNumber, mevBlock.Miner, receipts) {
+		decimals, err := t.tokenDecimals(ctx, p.Token)
+		if err != nil {
+			t.log.Warn("failed to get the decimals of a token", "token", p.Token, "error", err)
+		} else {
+			p.Decimals = &decimals
+		}
+		mevBlock.TokenPayments = append(mevBlock.TokenPayments, p)
+	}
+	mevBlock.TokenTotals = database.TokenTotals(mevBlock.TokenPayments)
+	t.recordDetected(ctx, MEVKindTokenPayment, len(mevBlock.TokenPayments))
+}
+
+// coinbaseBribes sums the coinbase transfers per tx; hashes are in the order of txs
+func coinbaseBribes(txs []*database.MEVTransaction) (bribes map[common.Hash]*big.Int, hashes []common.Hash) {
+	bribes = map[common.Hash]*big.Int{}
+	for _, tx := range txs {
+		hash := tx.TXHash
+		if _, ok := bribes[hash]; !ok {
+			bribes[hash] = new(big.Int)
+			hashes = append(hashes, hash)
+		}
+		bribes[hash].Add(bribes[hash], tx.Value)
+	}
+	return bribes, hashes
+}
+
+// poolTokens returns token0 and token1 of a Uniswap pool
+func (t *Tracer) poolTokens(ctx context.Context, pool common.Address) ([2]common.Address, error) {
+	t.tokens.mu.Lock()
+	tokens, ok := t.tokens.tokens[pool]
+	t.tokens.mu.Unlock()
+	if ok {
+		return tokens, nil
+	}
+
+	for i, selector := range []string{Token0Selector, Token1Selector} {
+		callCtx, cancel := context.WithTimeout(ctx, t.CallTimeout)
+		resp, err := t.call(callCtx, CallRPC, map[string]string{"to": string(pool), "data": selector}, "latest")
+		cancel()
+		if err != nil {
+			return tokens, err
+		}
+		word, err := resp.GetString()
+		if err != nil {
+			t.recordRPCError(ctx, CallRPC)
+			return tokens, err
+		}
+		if tokens[i] = wordAddress(word); tokens[i] == "" {
+			return tokens, fmt.Errorf("%w: %s is not a pool", ErrUnexpectedResponse, pool)
+		}
+	}
+
+	t.tokens.mu.Lock()
+	defer t.tokens.mu.Unlock()
+	if t.tokens.tokens == nil {
+		t.tokens.tokens = map[common.Address][2]common.Address{}
+	}
+	t.tokens.tokens[pool] = tokens
+	return tokens, nil
+}
+
+// tokenDecimals returns the decimals of an ERC-20 token
+func (t *Tracer) tokenDecimals(ctx context.Context, token common.Address) (uint8, error) {
+	t.decimals.mu.Lock()
+	decimals, ok := t.decimals.decimals[token]
+	t.decimals.mu.Unlock()
+	if ok {
+		return decimals, nil
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, t.CallTimeout)
+	defer cancel()
+	resp, err := t.call(callCtx, CallRPC, map[string]string{"to": string(token), "data": DecimalsSelector}, "latest")
+	if err != nil {
+		return 0, err
+	}
+	word, err := resp.GetString()
+	if err != nil {
+		t.recordRPCError(ctx, CallRPC)
+		return 0, err
+	}
+	words := dataWords(word)
+	if len(words) != 1 || new(big.Int).SetBytes(words[0]).Cmp(big.NewInt(255)) > 0 {
+		return 0, fmt.Errorf("%w: %s has no decimals", ErrUnexpectedResponse, token)
+	}
+	decimals = words[0][31]
+
+	t.decimals.mu.Lock()
+	defer t.decimals.mu.Unlock()
+	if t.decimals.decimals == nil {
+		t.decimals.decimals = map[common.Address]uint8{}
+	}
+	t.decimals.decimals[token] = decimals
+	return decimals, nil
+}
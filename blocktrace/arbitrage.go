@@ -0,0 +1,78 @@
+package blocktrace
+
+import (
+	"math/big"
+
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database"
+)
+
+// WETHAddress is the wrapped ether token on mainnet; bribes can only be compared to profits in WETH
+const WETHAddress common.Address = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+
+// tokenSwap is a swap with its tokens resolved, seen from the trader
+type tokenSwap struct {
+	*Swap
+	TokenIn   common.Address
+	TokenOut  common.Address
+	AmountIn  *big.Int
+	AmountOut *big.Int
+}
+
+// newTokenSwap resolves the direction of a swap on a pool with the given tokens
+func newTokenSwap(s *Swap, tokens [2]common.Address) *tokenSwap {
+	if s.ZeroForOne() {
+		return &tokenSwap{
+			Swap:      s,
+			TokenIn:   tokens[0],
+			TokenOut:  tokens[1],
+			AmountIn:  s.Amount0,
+			AmountOut: new(big.Int).Neg(s.Amount1),
+		}
+	}
+	return &tokenSwap{
+		Swap:      s,
+		TokenIn:   tokens[1],
+		TokenOut:  tokens[0],
+		AmountIn:  s.Amount1,
+		AmountOut: new(big.Int).Neg(s.Amount0),
+	}
+}
+
+// detectArbitrage checks whether the swaps of a single transaction, in log order, form a cyclic arbitrage:
+// at least two swaps, each selling the token bought by the previous one, starting and ending in the same token.
+// It returns nil if they don't.
+func detectArbitrage(swaps []*tokenSwap) *database.Arbitrage {
+	if len(swaps) < 2 {
+		return nil
+	}
+	first, last := swaps[0], swaps[len(swaps)-1]
+	if first.TokenIn != last.TokenOut {
+		return nil
+	}
+	path := []common.Address{first.TokenIn}
+	pools := []common.Address{}
+	for i, s := range swaps {
+		if i > 0 && s.TokenIn != swaps[i-1].TokenOut {
+			return nil
+		}
+		path = append(path, s.TokenOut)
+		pools = append(pools, s.Pool)
+	}
+	return &database.Arbitrage{
+		Path:        path,
+		Pools:       pools,
+		ProfitToken: first.TokenIn,
+		Profit:      new(big.Int).Sub(last.AmountOut, first.AmountIn),
+	}
+}
+
+// setBribe sets the coinbase bribe of an arbitrage, and its ratio to the profit if comparable
+func setBribe(arb *database.Arbitrage, bribe *big.Int) {
+	arb.Bribe = bribe
+	if arb.ProfitToken != WETHAddress || arb.Profit.Sign() <= 0 {
+		return
+	}
+	ratio, _ := new(big.Rat).SetFrac(bribe, arb.Profit).Float64()
+	arb.BribeRatio = &ratio
+}
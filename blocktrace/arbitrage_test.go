@@ -0,0 +1,98 @@
+package blocktrace
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/flashbots/go-utils/rpcclient"
+	"github.com/golang/mock/gomock"
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/holisticode/mev-rpc/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const testPool2 common.Address = "0xb4e16d0168e52d35cacd2c6185b44281ec28c9dc"
+
+// TestDetectArbitrage() tests the cycle matching of the swaps of a tx
+func TestDetectArbitrage(t *testing.T) {
+	tokens := [2]common.Address{testToken0, testToken1}
+	// sells 1000 token1 for 2000 token0, then 2000 token0 for 1100 token1
+	swaps := []*tokenSwap{
+		newTokenSwap(&Swap{Pool: testPool, Amount0: big.NewInt(-2000), Amount1: big.NewInt(1000)}, tokens),
+		newTokenSwap(&Swap{Pool: testPool2, Amount0: big.NewInt(2000), Amount1: big.NewInt(-1100)}, tokens),
+	}
+	arb := detectArbitrage(swaps)
+	require.Equal(t, &database.Arbitrage{
+		Path:        []common.Address{testToken1, testToken0, testToken1},
+		Pools:       []common.Address{testPool, testPool2},
+		ProfitToken: testToken1,
+		Profit:      big.NewInt(100),
+	}, arb)
+
+	setBribe(arb, big.NewInt(80))
+	require.Equal(t, big.NewInt(80), arb.Bribe)
+	require.NotNil(t, arb.BribeRatio)
+	require.InDelta(t, 0.8, *arb.BribeRatio, 1e-9)
+
+	// no cycle: the second swap sells token1 again
+	swaps[1] = newTokenSwap(&Swap{Pool: testPool2, Amount0: big.NewInt(-2000), Amount1: big.NewInt(1100)}, tokens)
+	require.Nil(t, detectArbitrage(swaps))
+	require.Nil(t, detectArbitrage(swaps[:1]))
+
+	// a profit in another token has no bribe ratio
+	arb = detectArbitrage([]*tokenSwap{
+		newTokenSwap(&Swap{Pool: testPool, Amount0: big.NewInt(1000), Amount1: big.NewInt(-2000)}, tokens),
+		newTokenSwap(&Swap{Pool: testPool2, Amount0: big.NewInt(-1100), Amount1: big.NewInt(2000)}, tokens),
+	})
+	require.Equal(t, testToken0, arb.ProfitToken)
+	setBribe(arb, big.NewInt(80))
+	require.Nil(t, arb.BribeRatio)
+}
+
+// TestAnalyzeArbitrages() tests that an arbitrage is attached to all the coinbase transfers of its tx
+func TestAnalyzeArbitrages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRPCClient := mocks.NewMockRPCClient(ctrl)
+	tracer := NewBlockTracer(mockRPCClient, mocks.NewMockMEVTraceStorage(ctrl), getTestMetrics(t), common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+
+	word := func(address common.Address) *rpcclient.RPCResponse {
+		return &rpcclient.RPCResponse{Result: "0x000000000000000000000000" + string(address[2:])}
+	}
+	for _, pool := range []common.Address{testPool, testPool2} {
+		mockRPCClient.EXPECT().Call(gomock.Any(), CallRPC, map[string]string{"to": string(pool), "data": Token0Selector}, "latest").
+			Return(word(testToken0), nil).Times(1)
+		mockRPCClient.EXPECT().Call(gomock.Any(), CallRPC, map[string]string{"to": string(pool), "data": Token1Selector}, "latest").
+			Return(word(testToken1), nil).Times(1)
+	}
+
+	arbReceipt := v3Receipt(1, testAttacker, testPool, -2000, 1000)
+	arbReceipt.Logs = append(arbReceipt.Logs, Log{
+		Address:  testPool2,
+		Topics:   []string{UniswapV3SwapTopic, "0x00", "0x00"},
+		Data:     encodeWords(2000, -1100, 0, 0, 0),
+		LogIndex: "0x1",
+	})
+	receipts := []*Receipt{
+		arbReceipt,
+		// the same cycle by a tx not paying the coinbase is ignored
+		v3Receipt(2, testVictim, testPool, -20, 10),
+	}
+	txs := []*database.MEVTransaction{
+		{TXHash: "0x01", Value: big.NewInt(30)},
+		{TXHash: "0x01", Value: big.NewInt(50), TraceAddress: []uint64{0}},
+		{TXHash: "0x03", Value: big.NewInt(1)},
+	}
+	tracer.analyzeBlock(t.Context(), receipts, &database.MEVBlock{BlockNumber: 21_000_042}, txs)
+
+	require.NotNil(t, txs[0].Arbitrage)
+	require.Same(t, txs[0].Arbitrage, txs[1].Arbitrage)
+	require.Nil(t, txs[2].Arbitrage)
+	require.Equal(t, []common.Address{testPool, testPool2}, txs[0].Arbitrage.Pools)
+	require.Equal(t, big.NewInt(100), txs[0].Arbitrage.Profit)
+	require.Equal(t, big.NewInt(80), txs[0].Arbitrage.Bribe)
+	require.InDelta(t, 0.8, *txs[0].Arbitrage.BribeRatio, 1e-9)
+}
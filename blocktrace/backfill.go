@@ -0,0 +1,102 @@
+package blocktrace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/holisticode/mev-rpc/database"
+	"go.uber.org/atomic"
+)
+
+// BackfillShards claims shards within from..to from queue and backfills them, until no shard is left to claim.
+// The lease of the shard being backfilled is renewed while the worker is alive,
+// so that only shards of crashed workers are handed out again.
+// It returns the number of blocks which could not be processed.
+func (t *Tracer) BackfillShards(
+	ctx context.Context,
+	queue database.BackfillQueue,
+	from, to uint64,
+	worker string,
+	lease time.Duration,
+) (failed uint64, err error) {
+	for {
+		shard, err := queue.ClaimBackfillShard(ctx, from, to, worker, lease)
+		if errors.Is(err, database.ErrNotFound) {
+			t.log.Info("No backfill shards left", "worker", worker)
+			return failed, nil
+		}
+		if err != nil {
+			t.recordStorageError(ctx)
+			return failed, fmt.Errorf("failed to claim backfill shard: %w", err)
+		}
+		t.log.Info("Claimed backfill shard", "worker", worker, "shard", shard.ID, "from", shard.FromBlock, "to", shard.ToBlock)
+		n, err := t.backfillShard(ctx, queue, worker, lease, shard)
+		failed += n
+		if err != nil {
+			return failed, err
+		}
+	}
+}
+
+// backfillShard backfills a single shard while renewing its lease, then marks it as done
+func (t *Tracer) backfillShard(
+	ctx context.Context,
+	queue database.BackfillQueue,
+	worker string,
+	lease time.Duration,
+	shard *database.BackfillShard,
+) (uint64, error) {
+	shardCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// if the lease is lost (e.g. we were stalled for too long), another worker
+	// took the shard over, and we stop working on it
+	var lost atomic.Bool
+	go func() {
+		ticker := time.NewTicker(lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-shardCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			err := queue.RenewBackfillLease(shardCtx, shard.ID, worker, lease)
+			switch {
+			case errors.Is(err, database.ErrNotFound):
+				t.log.Warn("Lost the lease on backfill shard", "worker", worker, "shard", shard.ID)
+				lost.Store(true)
+				cancel()
+				return
+			case err != nil && shardCtx.Err() == nil:
+				// we keep trying, the lease might not have expired yet
+				t.recordStorageError(ctx)
+				t.log.Error("Failed to renew backfill lease", "worker", worker, "shard", shard.ID, "error", err)
+			}
+		}
+	}()
+
+	failed, err := t.Backfill(shardCtx, shard.FromBlock, shard.ToBlock)
+	if lost.Load() {
+		return failed, nil
+	}
+	if err != nil {
+		return failed, err
+	}
+	cancel()
+
+	err = queue.CompleteBackfillShard(ctx, shard.ID, worker, failed)
+	if errors.Is(err, database.ErrNotFound) {
+		// another worker took the shard over in the meantime and will complete it
+		t.log.Warn("Lost the lease on backfill shard before completing it", "worker", worker, "shard", shard.ID)
+		return failed, nil
+	}
+	if err != nil {
+		t.recordStorageError(ctx)
+		return failed, fmt.Errorf("failed to complete backfill shard %d: %w", shard.ID, err)
+	}
+	t.log.Info("Completed backfill shard", "worker", worker, "shard", shard.ID, "failed", failed)
+	return failed, nil
+}
@@ -0,0 +1,72 @@
+package blocktrace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/holisticode/mev-rpc/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBackfillShards() tests that a worker backfills and completes shards until none is left
+func TestBackfillShards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	mockQueue := mocks.NewMockBackfillQueue(ctrl)
+	mockRPCClient := mocks.NewMockRPCClient(ctrl)
+
+	shard := &database.BackfillShard{ID: 7, FromBlock: 22391065, ToBlock: 22391065}
+	q1 := mockQueue.EXPECT().ClaimBackfillShard(gomock.Any(), uint64(22391064), uint64(22391070), "worker-1", time.Hour).Return(shard, nil)
+	s1 := mockStorage.EXPECT().GetMEVBlock(gomock.Any(), "22391065").After(q1).Return(nil, database.ErrNotFound)
+	r1 := mockRPCClient.EXPECT().Call(gomock.Any(), TraceBlockRPC, "0x155a919").After(s1).Return(getJSON(t, "./testdata/trace_block.json"), nil)
+	r2 := mockRPCClient.EXPECT().Call(gomock.Any(), BlockByHashRPC, gomock.Any()).After(r1).Return(getJSON(t, "./testdata/block_hash.json"), nil)
+	s2 := mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any(), gomock.Any()).After(r2).Return(nil)
+	q2 := mockQueue.EXPECT().CompleteBackfillShard(gomock.Any(), uint64(7), "worker-1", uint64(0)).After(s2).Return(nil)
+	mockQueue.EXPECT().ClaimBackfillShard(gomock.Any(), uint64(22391064), uint64(22391070), "worker-1", time.Hour).After(q2).Return(nil, database.ErrNotFound)
+
+	tracer := NewBlockTracer(mockRPCClient, mockStorage, getTestMetrics(t), common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+	failed, err := tracer.BackfillShards(t.Context(), mockQueue, 22391064, 22391070, "worker-1", time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), failed)
+}
+
+// TestBackfillShardsLostLease() tests that a worker abandons a shard once its lease was taken over
+func TestBackfillShardsLostLease(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	mockQueue := mocks.NewMockBackfillQueue(ctrl)
+	mockRPCClient := mocks.NewMockRPCClient(ctrl)
+
+	lease := 30 * time.Millisecond
+	shard := &database.BackfillShard{ID: 7, FromBlock: 22391065, ToBlock: 22391066}
+	q1 := mockQueue.EXPECT().ClaimBackfillShard(gomock.Any(), uint64(22391064), uint64(22391070), "worker-1", lease).Return(shard, nil)
+	// the worker stalls on the first block...
+	s1 := mockStorage.EXPECT().GetMEVBlock(gomock.Any(), "22391065").After(q1).
+		DoAndReturn(func(ctx context.Context, _ string) (*database.MEVBlock, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	// ...meanwhile its lease expired and the shard was claimed by another worker
+	mockQueue.EXPECT().RenewBackfillLease(gomock.Any(), uint64(7), "worker-1", lease).Return(database.ErrNotFound)
+	// so the shard isn't completed, and the worker moves on
+	mockQueue.EXPECT().ClaimBackfillShard(gomock.Any(), uint64(22391064), uint64(22391070), "worker-1", lease).After(s1).Return(nil, database.ErrNotFound)
+
+	tracer := NewBlockTracer(mockRPCClient, mockStorage, getTestMetrics(t), common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+	failed, err := tracer.BackfillShards(t.Context(), mockQueue, 22391064, 22391070, "worker-1", lease)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), failed)
+}
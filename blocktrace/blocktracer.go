@@ -13,42 +13,140 @@ import (
 	"math/big"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/flashbots/go-utils/rpcclient"
+	"github.com/holisticode/mev-rpc/common"
 	"github.com/holisticode/mev-rpc/database"
+	"github.com/holisticode/mev-rpc/metrics"
+	"github.com/holisticode/mev-rpc/prices"
+	"github.com/holisticode/mev-rpc/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/atomic"
 )
 
 const (
-	CallTimeout       = 10 * time.Second
-	PollingInterval   = 6 * time.Second
-	LastBlockRPC      = "eth_blockNumber"
-	TraceBlockRPC     = "trace_block"
-	BlockByHashRPC    = "eth_getBlockByHash"
-	HexPrefix         = "0x"
-	FlashbotsCoinbase = "0xdafea492d9c6733ae3d56b7ed1adb60692c98bc5"
+	DefaultCallTimeout     = 10 * time.Second
+	DefaultPollingInterval = 6 * time.Second
+	LastBlockRPC           = "eth_blockNumber"
+	TraceBlockRPC          = "trace_block"
+	BlockByHashRPC         = "eth_getBlockByHash"
+	HexPrefix              = "0x"
+	FlashbotsCoinbase      = "0xdafea492d9c6733ae3d56b7ed1adb60692c98bc5"
 )
 
-var ErrEmptyBlock = errors.New("empty block")
+var (
+	ErrEmptyBlock         = errors.New("empty block")
+	ErrUnexpectedResponse = errors.New("unexpected response")
+	// errSaveFailed is returned by processBlock for a block which was traced, but not saved
+	errSaveFailed = errors.New("failed to save block")
+)
+
+// Elector elects a single tracer to index among the replicas sharing the same storage.
+// Run calls lead whenever this replica becomes leader, with a context which is
+// cancelled once leadership is lost, and returns once ctx is done.
+type Elector interface {
+	Run(ctx context.Context, lead func(ctx context.Context))
+}
 
 // Tracer is the main object used to query the chain
 type Tracer struct {
 	storage   database.MEVTraceStorage
 	rpcClient rpcclient.RPCClient
+	metrics   *metrics.MetricsServer
 	log       *slog.Logger
+
+	// CallTimeout bounds every RPC call to the chain node
+	CallTimeout time.Duration
+	// Analyze enables the detection of MEV (e.g. sandwiches) from the receipts of each block.
+	// It requires the eth_getBlockReceipts RPC, and must be set before Start.
+	Analyze  bool
+	tokens   poolTokens
+	decimals tokenDecimals
+	// Prices is optional; with it, the MEV of each block is valued in USD at the time of the block
+	Prices prices.Provider
+	// ValueStored makes Backfill value the stored blocks without USD values with Prices
+	ValueStored bool
+
+	// chainHead is the latest block number seen on chain
+	chainHead atomic.Uint64
+	// lastProcessed is the latest block number the tracer has handled
+	lastProcessed atomic.Uint64
+	// synced is set once the tracer caught up with the chain head for the first time
+	synced atomic.Bool
+
+	// elector is optional; without it the tracer always indexes
+	elector Elector
+	// leading is set while the tracer is indexing
+	leading atomic.Bool
+
+	// lifecycle: Stop cancels the context of Start, done is closed once Start returned
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	stopped  bool
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// SyncStatus describes how far the tracer got indexing the chain
+type SyncStatus struct {
+	LastProcessedBlock uint64 `json:"lastProcessedBlock"` //nolint:tagliatelle
+	ChainHead          uint64 `json:"chainHead"`          //nolint:tagliatelle
+	Lag                uint64 `json:"lag"`
+	// CatchingUp is true until the tracer reaches the chain head for the first time
+	CatchingUp bool `json:"catchingUp"` //nolint:tagliatelle
+	// Standby is true if another replica was elected to index
+	Standby bool `json:"standby"`
 }
 
 // NewBlockTracer creates a new tracer.
 // Params:
 // * rpcClient for querying nodes (can be mocked)
 // * storage interface object for storying and querying the data we're interested in (can be mocked)
+// * metricsSrv the metrics server to record the tracer's metrics to, metrics are not recorded if nil
 // * log logger object
-func NewBlockTracer(rpcClient rpcclient.RPCClient, storage database.MEVTraceStorage, log *slog.Logger) *Tracer {
-	return &Tracer{
-		storage,
-		rpcClient,
-		log,
+func NewBlockTracer(
+	rpcClient rpcclient.RPCClient,
+	storage database.MEVTraceStorage,
+	metricsSrv *metrics.MetricsServer,
+	log *slog.Logger,
+) *Tracer {
+	t := &Tracer{
+		storage:   storage,
+		rpcClient: rpcClient,
+		metrics:   metricsSrv,
+		log:       log,
+		done:      make(chan struct{}),
+
+		CallTimeout: DefaultCallTimeout,
 	}
+	t.registerGauges()
+	return t
+}
+
+// SyncStatus returns the current sync state of the tracer.
+// It is safe to be called concurrently with Start.
+func (t *Tracer) SyncStatus() SyncStatus {
+	head, last := t.chainHead.Load(), t.lastProcessed.Load()
+	var lag uint64
+	if last < head {
+		lag = head - last
+	}
+	return SyncStatus{
+		LastProcessedBlock: last,
+		ChainHead:          head,
+		Lag:                lag,
+		CatchingUp:         !t.synced.Load(),
+		Standby:            t.elector != nil && !t.leading.Load(),
+	}
+}
+
+// SetElector makes the tracer index only while elected leader.
+// It must be called before Start.
+func (t *Tracer) SetElector(elector Elector) {
+	t.elector = elector
 }
 
 // Start starts to query the chain and retrieve data
@@ -63,7 +161,38 @@ func NewBlockTracer(rpcClient rpcclient.RPCClient, storage database.MEVTraceStor
 // * ctx a context (mainly for canceling the loop)
 // * pollingInterval duration (allows to pass custom interval for quicker testing)
 // NOTE: The fetching of individual blocks could be parallelized, improving performance
+//
+// Start returns once ctx is done or Stop is called, and must only be called once.
+// If an elector is set, the tracer only indexes while it is the leader.
 func (t *Tracer) Start(ctx context.Context, pollingInterval time.Duration) {
+	defer t.doneOnce.Do(func() { close(t.done) })
+	t.mu.Lock()
+	if t.stopped {
+		t.mu.Unlock()
+		return
+	}
+	ctx, t.cancel = context.WithCancel(ctx)
+	t.mu.Unlock()
+	defer t.cancel()
+
+	if t.elector == nil {
+		t.leading.Store(true)
+		t.run(ctx, pollingInterval)
+		return
+	}
+	t.elector.Run(ctx, func(ctx context.Context) {
+		t.log.Info("Elected to index")
+		// the leader before us may have left the DB behind the chain head
+		t.synced.Store(false)
+		t.leading.Store(true)
+		defer t.leading.Store(false)
+		t.run(ctx, pollingInterval)
+		t.log.Info("Stopped indexing")
+	})
+}
+
+// run polls the chain and indexes new blocks until ctx is done
+func (t *Tracer) run(ctx context.Context, pollingInterval time.Duration) {
 	// loop endlessly...
 	for {
 		select {
@@ -75,20 +204,19 @@ func (t *Tracer) Start(ctx context.Context, pollingInterval time.Duration) {
 		}
 		t.log.Debug("Polling chain for head block...")
 		// first get the latest saved block on the DB
-		lastDBBlock, err := t.storage.LatestBlock()
+		lastDBBlock, err := t.storage.LatestBlock(ctx)
 		if err != nil {
-			// TODO: add to error metrics
-			t.log.Error("failed rpc call", "endpoint", LastBlockRPC, "error", err)
+			t.recordStorageError(ctx)
+			t.log.Error("failed to get latest block from storage", "error", err)
 			// no use to do anything at this point
 			// TODO:maybe an error counter; after a threshold stop or panic server
 			continue
 		}
 		// now get the latest block from the chain
-		ctx, cancel := context.WithTimeout(context.Background(), CallTimeout)
-		resp, err := t.rpcClient.Call(ctx, LastBlockRPC, nil)
-		defer cancel()
+		callCtx, cancel := context.WithTimeout(ctx, t.CallTimeout)
+		resp, err := t.call(callCtx, LastBlockRPC, nil)
+		cancel()
 		if err != nil {
-			// TODO: add to error metrics
 			t.log.Error("failed rpc call", "endpoint", LastBlockRPC, "error", err)
 			// no use to do anything at this point
 			// TODO:maybe an error counter; after a threshold stop or panic server
@@ -96,7 +224,7 @@ func (t *Tracer) Start(ctx context.Context, pollingInterval time.Duration) {
 		}
 		lastChainBlockStr, err := resp.GetString()
 		if err != nil {
-			// TODO: add to error metrics
+			t.recordRPCError(ctx, LastBlockRPC)
 			t.log.Error("failed to get string from response", "endpoint", LastBlockRPC, "error", err)
 			// no use to do anything at this point; however, this error should maybe be handled better:
 			// we got data but couldn't interpret it
@@ -105,23 +233,47 @@ func (t *Tracer) Start(ctx context.Context, pollingInterval time.Duration) {
 		lastChainBlockStr = sanitizeHexString(lastChainBlockStr)
 		lastChainBlock, err := strconv.ParseUint(lastChainBlockStr, 16, 64)
 		if err != nil {
-			// TODO: add to error metrics
+			t.recordRPCError(ctx, LastBlockRPC)
 			t.log.Error("failed to parse string into uint", "endpoint", LastBlockRPC, "error", err)
 			// no use to do anything at this point; however, this error should maybe be handled better:
 			// we got data but couldn't interpret it
 			continue
 		}
 		t.log.Debug("last chain block", "number", lastChainBlock)
+		t.chainHead.Store(lastChainBlock)
 
 		if lastDBBlock < lastChainBlock {
 			// our database has a lower chain block number than the last number on chain
-			t.catchUp(lastDBBlock, lastChainBlock)
+			t.catchUp(ctx, lastDBBlock, lastChainBlock)
 		} else {
+			t.lastProcessed.Store(lastDBBlock)
 			t.log.Info("DB is in sync with chain head")
 		}
+		// catching up may have been interrupted by Stop
+		if ctx.Err() == nil {
+			t.synced.Store(true)
+		}
 	}
 }
 
+// Stop makes Start return. The block being processed is aborted:
+// in-flight RPC calls and queries are cancelled, and a block which
+// was not completely saved is rolled back, to be traced again on the next run.
+// Stop does not wait for Start to return, use Wait for that.
+func (t *Tracer) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopped = true
+	if t.cancel != nil {
+		t.cancel()
+	}
+}
+
+// Wait blocks until Start returned
+func (t *Tracer) Wait() {
+	<-t.done
+}
+
 // sanitizeHexString() removes 0x if needed from a hex string
 func sanitizeHexString(s string) string {
 	if strings.HasPrefix(s, HexPrefix) {
@@ -132,72 +284,133 @@ func sanitizeHexString(s string) string {
 
 // catchUp runs a loop to catch up our database with the latest block on chain.
 // It loops from the last saved block number until the last known block on chain.
-// It ignores errors for non-existing blocks in between
-func (t *Tracer) catchUp(lastDBBlock, lastChainBlock uint64) {
+// It ignores errors for non-existing blocks in between, and stops when ctx is done
+func (t *Tracer) catchUp(ctx context.Context, lastDBBlock, lastChainBlock uint64) {
 	t.log.Info("Need to catch up with chain",
 		slog.Uint64("latest DB block", lastDBBlock),
 		slog.Uint64("latest chain block", lastChainBlock))
 
-	last := lastDBBlock
 	// iterate from our last DB block until the latest known on chain
-	for last <= lastChainBlock {
-		// get data from the trace_block RPC
-		tB, err := t.traceBlock(last)
-		if err != nil {
-			// TODO: add to error metrics
-			// ignore error
-			last += 1
-			continue
+	for last := lastDBBlock; last <= lastChainBlock; last++ {
+		if ctx.Err() != nil {
+			t.log.Info("Catching up interrupted", "last", last)
+			return
+		}
+		// errors are ignored, we just continue with the next block.
+		// A block which was traced counts as processed even if saving it failed,
+		// unless saving was aborted by Stop.
+		err := t.processBlock(ctx, last)
+		if err == nil || (errors.Is(err, errSaveFailed) && ctx.Err() == nil) {
+			t.lastProcessed.Store(last)
 		}
-		traceBlock := *tB
-		blockHash := traceBlock[0].BlockHash
-		ctx, cancel := context.WithTimeout(context.Background(), CallTimeout)
-		defer cancel()
+		t.log.Debug("getting next block", "last", last+1)
+	}
+	t.log.Info("Caught up with chain head")
+}
 
-		// get the data from the actual block from the eth_getBlockByHash RPC
-		actualBlock, err := t.rpcClient.Call(ctx, BlockByHashRPC, blockHash, false)
-		if err != nil {
-			// TODO: add to error metrics
-			t.log.Error("failed rpc call", "endpoint", BlockByHashRPC, "error", err)
-			// ignore, however this type of error should be handled better, as we got data but couldn't interpret it
-			last += 1
-			continue
+// Backfill traces the blocks from `from` to `to` (both inclusive), once,
+// skipping blocks which are already stored (but valuing them with ValueStored).
+// Blocks which fail to be processed are skipped as well; their count is returned.
+// It returns an error only if the storage can't be queried or ctx is done.
+func (t *Tracer) Backfill(ctx context.Context, from, to uint64) (failed uint64, err error) {
+	t.log.Info("Backfilling blocks", "from", from, "to", to)
+	for blockNum := from; blockNum <= to; blockNum++ {
+		if err := ctx.Err(); err != nil {
+			return failed, err
 		}
-		t.log.Debug("Fetched block by hash")
-		var block Block
-		err = actualBlock.GetObject(&block)
-		if err != nil {
-			// TODO: add to error metrics
-			t.log.Error("failed to get block from response", "endpoint", BlockByHashRPC, "error", err)
-			// ignore, however this type of error should be handled better, as we got data but couldn't interpret it
-			last += 1
+		stored, err := t.storage.GetMEVBlock(ctx, strconv.FormatUint(blockNum, 10))
+		if err == nil {
+			t.log.Debug("block already stored", "block", blockNum)
+			if err := t.valueStoredBlock(ctx, stored); err != nil {
+				failed++
+			}
 			continue
 		}
+		if !errors.Is(err, database.ErrNotFound) {
+			t.recordStorageError(ctx)
+			return failed, fmt.Errorf("failed to check block %d: %w", blockNum, err)
+		}
+		if err := t.processBlock(ctx, blockNum); err != nil {
+			failed++
+		}
+	}
+	t.log.Info("Backfill done", "from", from, "to", to, "failed", failed)
+	return failed, nil
+}
 
-		// we got the data for the block; extract tx data from it
-		t.handleTxs(tB, &block, blockHash, last)
+// processBlock traces a single block and stores its MEV data, if any.
+// Errors are already logged and recorded to metrics, the caller only needs
+// to decide whether to skip the block.
+func (t *Tracer) processBlock(ctx context.Context, blockNum uint64) (err error) {
+	ctx, span := tracing.Start(ctx, "tracer.processBlock", trace.SpanKindInternal,
+		attribute.Int64("block.number", int64(blockNum))) //nolint:gosec
+	defer func() { tracing.End(span, err) }()
 
-		// handle next block
-		last += 1
-		t.log.Debug("getting next block", "last", last)
+	// get data from the trace_block RPC
+	tB, err := t.traceBlock(ctx, blockNum)
+	if err != nil {
+		reason := SkipReasonTraceFailed
+		if errors.Is(err, ErrEmptyBlock) {
+			reason = SkipReasonEmptyBlock
+		}
+		t.recordBlockSkipped(ctx, reason)
+		return err
 	}
-	t.log.Info("Caught up with chain head")
+	traceBlock := *tB
+	blockHash := traceBlock[0].BlockHash
+	callCtx, cancel := context.WithTimeout(ctx, t.CallTimeout)
+	defer cancel()
+
+	// get the data from the actual block from the eth_getBlockByHash RPC
+	actualBlock, err := t.call(callCtx, BlockByHashRPC, blockHash, false)
+	if err != nil {
+		t.recordBlockSkipped(ctx, SkipReasonGetBlockFailed)
+		t.log.Error("failed rpc call", "endpoint", BlockByHashRPC, "error", err)
+		return err
+	}
+	t.log.Debug("Fetched block by hash")
+	var block Block
+	err = actualBlock.GetObject(&block)
+	if err != nil {
+		// this type of error should be handled better, as we got data but couldn't interpret it
+		t.recordRPCError(ctx, BlockByHashRPC)
+		t.recordBlockSkipped(ctx, SkipReasonGetBlockFailed)
+		t.log.Error("failed to get block from response", "endpoint", BlockByHashRPC, "error", err)
+		return err
+	}
+
+	// the receipts are only needed to detect MEV
+	var receipts []*Receipt
+	if t.Analyze {
+		receipts = t.blockReceipts(ctx, blockHash)
+	}
+
+	// we got the data for the block; extract tx data from it
+	if err = t.handleTxs(ctx, tB, &block, receipts, blockHash, blockNum); err != nil {
+		t.recordBlockSkipped(ctx, SkipReasonSaveFailed)
+		return fmt.Errorf("%w: %w", errSaveFailed, err)
+	}
+	t.recordBlockProcessed(ctx)
+	return nil
 }
 
 // handleTxs extracts the data we are interested in from a block,
-// and stores it into the DB
+// detects MEV in its receipts (if any), and stores it into the DB.
+// It only returns an error if the block could not be saved.
 func (t *Tracer) handleTxs(
+	ctx context.Context,
 	traceBlock *TraceBlockResponse,
 	block *Block,
-	blockHash string,
+	receipts []*Receipt,
+	blockHash common.Hash,
 	blockNum uint64,
-) {
+) error {
 	// we might be interested to know if the coinbase address was a flashbot one
 	isFlashbotMiner := block.Miner == FlashbotsCoinbase
 	if isFlashbotMiner {
 		t.log.Debug("this block was mined by flashbots", "hash", blockHash)
 	}
-	t.log.Debug("miner", slog.String("address", block.Miner))
+	t.log.Debug("miner", slog.String("address", string(block.Miner)))
 
 	txs := make([]*database.MEVTransaction, 0)
 	total := big.NewInt(0)
@@ -214,62 +427,124 @@ func (t *Tracer) handleTxs(
 			val := new(big.Int)
 			val, ok := val.SetString(valStr, 16)
 			if !ok {
-				// TODO: add to log metrics
 				// this should actually never happen
 				t.log.Error("Failed to set the transaction value!", "val", tx.Action.Value)
 				continue
 			}
+			// not all traces report the gas used, so we don't skip the tx if it's missing
+			var gasUsed uint64
+			if gas := sanitizeHexString(tx.Result.GasUsed); gas != "" {
+				var err error
+				if gasUsed, err = strconv.ParseUint(gas, 16, 64); err != nil {
+					t.log.Error("failed to parse gas used", "gasUsed", tx.Result.GasUsed, "error", err)
+				}
+			}
 			// create an object to store the tx
 			mtx := &database.MEVTransaction{
-				TXHash:      tx.TransactionHash,
-				From:        tx.Action.From,
-				To:          tx.Action.To,
-				Value:       val,
-				BlockNumber: blockNum,
+				TXHash:       tx.TransactionHash,
+				From:         tx.Action.From,
+				To:           tx.Action.To,
+				Value:        val,
+				BlockNumber:  blockNum,
+				Position:     tx.TransactionPosition,
+				TraceAddress: tx.TraceAddress,
+				GasUsed:      gasUsed,
 			}
 			total = total.Add(total, val)
 			txs = append(txs, mtx)
 		}
 	}
-	// only if we had any relevant txs at all...
-	if len(txs) > 0 {
-		// ...we create a block representation
-		mevBlock := &database.MEVBlock{
-			BlockNumber:     blockNum,
-			BlockHash:       blockHash,
-			Miner:           block.Miner,
-			IsFlashbotMiner: isFlashbotMiner,
-			TotalMinerValue: total,
-		}
+	// the timestamp is only used for aggregations, so we don't skip the block if it's malformed
+	timestamp, err := strconv.ParseUint(sanitizeHexString(block.Timestamp), 16, 64)
+	if err != nil {
+		t.log.Warn("failed to parse block timestamp, storing the block without it",
+			"block", blockNum, "timestamp", block.Timestamp, "error", err)
+		timestamp = 0
+	}
+	// we create a block representation...
+	mevBlock := &database.MEVBlock{
+		BlockNumber:     blockNum,
+		BlockHash:       blockHash,
+		Miner:           block.Miner,
+		IsFlashbotMiner: isFlashbotMiner,
+		TotalMinerValue: total,
+		Timestamp:       timestamp,
+	}
+	t.valueInUSD(ctx, mevBlock, txs)
+	t.analyzeBlock(ctx, receipts, mevBlock, txs)
+	// ...which is only stored if we had any relevant txs or detected MEV at all
+	if len(txs) > 0 || len(mevBlock.Sandwiches) > 0 || len(mevBlock.Liquidations) > 0 ||
+		len(mevBlock.TokenPayments) > 0 {
 		t.log.Debug("saving block and txs to DB...", "blockNumber", blockNum)
 		// ...and try to save it
-		if err := t.storage.SaveMEVBLock(mevBlock, txs); err != nil {
+		if err := t.storage.SaveMEVBLock(ctx, mevBlock, txs); err != nil {
 			// TODO: In this case, either retry, or catch up later...
 			// e.g. add to some queue or data structure for getting this block again
 			// or just retry storing later
+			t.recordStorageError(ctx)
 			t.log.Error("Failed to save MEV block to database!", "error", err)
-		} else {
-			t.log.Info("Saved MEV block to database", "block", blockNum)
+			return err
 		}
+		t.recordMEV(ctx, len(txs), total)
+		t.log.Info("Saved MEV block to database", "block", blockNum)
+	}
+	return nil
+}
+
+// valueInUSD sets the ETH/USD price of the block and the USD values of its transfers, if there is a price
+func (t *Tracer) valueInUSD(ctx context.Context, mevBlock *database.MEVBlock, txs []*database.MEVTransaction) {
+	// without a timestamp there is no price to look up
+	if t.Prices == nil || mevBlock.Timestamp == 0 {
+		return
+	}
+	price, err := t.Prices.ETHUSD(ctx, mevBlock.Timestamp)
+	if err != nil {
+		t.log.Warn("no ETH/USD price for block", "block", mevBlock.BlockNumber, "error", err)
+		return
+	}
+	total := prices.USDValue(mevBlock.TotalMinerValue, price)
+	mevBlock.ETHUSD = &price
+	mevBlock.TotalMinerValueUSD = &total
+	for _, tx := range txs {
+		value := prices.USDValue(tx.Value, price)
+		tx.ValueUSD = &value
+	}
+}
+
+// valueStoredBlock stores the USD values of a stored block without any, if ValueStored is set
+// and there is a price for it
+func (t *Tracer) valueStoredBlock(ctx context.Context, block *database.MEVBlock) error {
+	if !t.ValueStored || block.ETHUSD != nil {
+		return nil
+	}
+	t.valueInUSD(ctx, block, block.MEVTransactions)
+	if block.ETHUSD == nil {
+		return nil
+	}
+	if err := t.storage.SaveUSDValues(ctx, block); err != nil {
+		t.recordStorageError(ctx)
+		t.log.Error("Failed to save USD values of block", "block", block.BlockNumber, "error", err)
+		return err
 	}
+	t.log.Debug("Saved USD values of stored block", "block", block.BlockNumber)
+	return nil
 }
 
 // traceBlock executes the trace_block RPC call
-func (t *Tracer) traceBlock(block uint64) (*TraceBlockResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), CallTimeout)
+func (t *Tracer) traceBlock(ctx context.Context, block uint64) (*TraceBlockResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.CallTimeout)
 	defer cancel()
 	// number representation of the block we're going to fetch
 	fetch := fmt.Sprintf("0x%x", block)
 	t.log.Debug("Fetching...", slog.String("block", fetch))
-	resp, err := t.rpcClient.Call(ctx, TraceBlockRPC, fetch)
+	resp, err := t.call(ctx, TraceBlockRPC, fetch)
 	if err != nil {
-		// TODO: add to error metrics
 		t.log.Error("failed rpc call", "endpoint", TraceBlockRPC, "error", err)
 		return nil, err
 	}
 	var traceBlock *TraceBlockResponse
 	if err = resp.GetObject(&traceBlock); err != nil {
-		// TODO: add to error metrics
+		t.recordRPCError(ctx, TraceBlockRPC)
 		t.log.Error("failed to get block data from response", "endpoint", TraceBlockRPC, "error", err)
 		return nil, err
 	}
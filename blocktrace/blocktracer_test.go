@@ -1,10 +1,13 @@
 package blocktrace
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"io"
+	"log/slog"
+	"math/big"
 	"os"
 	"strings"
 	"testing"
@@ -13,7 +16,10 @@ import (
 	"github.com/flashbots/go-utils/rpcclient"
 	"github.com/golang/mock/gomock"
 	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/holisticode/mev-rpc/metrics"
 	"github.com/holisticode/mev-rpc/mocks"
+	"github.com/holisticode/mev-rpc/prices"
 	"github.com/stretchr/testify/require"
 )
 
@@ -30,14 +36,14 @@ func TestBlockTrace(t *testing.T) {
 	// First we return a fictitious number which will require to catch up.
 	// The test loads a fixed json testdata file, which has the latest block set to 2391066
 	// Therefore we will catcn up 3 blocks...
-	s1 := mockStorage.EXPECT().LatestBlock().Return(uint64(22391064), nil)
+	s1 := mockStorage.EXPECT().LatestBlock(gomock.Any()).Return(uint64(22391064), nil)
 	// ...so then we save 3 blocks...
-	s2 := mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any()).After(s1).Return(nil)
-	s3 := mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any()).After(s2).Return(nil)
-	s4 := mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any()).After(s3).Return(nil)
+	s2 := mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any(), gomock.Any()).After(s1).Return(nil)
+	s3 := mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any(), gomock.Any()).After(s2).Return(nil)
+	s4 := mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any(), gomock.Any()).After(s3).Return(nil)
 	// ...after which the loop will call for the latest block again.
 	// THIS IS THE SIGNAL THAT EVERYTHING WENT WELL, so we call the cancel function of the ctx, which will stop the loop and finish the test
-	mockStorage.EXPECT().LatestBlock().After(s4).Return(uint64(22391066), nil).Do(cancel)
+	mockStorage.EXPECT().LatestBlock(gomock.Any()).After(s4).Return(uint64(22391066), nil).Do(func(context.Context) { cancel() })
 
 	// create a mock instance for the RPC client
 	mockRPCClient := mocks.NewMockRPCClient(ctrl)
@@ -63,9 +69,228 @@ func TestBlockTrace(t *testing.T) {
 		Service: "test",
 		Version: common.Version,
 	})
-	tracer := NewBlockTracer(mockRPCClient, mockStorage, log)
+	tracer := NewBlockTracer(mockRPCClient, mockStorage, getTestMetrics(t), log)
+	// before starting, the tracer reports it still needs to catch up
+	require.True(t, tracer.SyncStatus().CatchingUp)
 	// Here is where the test actually starts!
 	tracer.Start(ctx, 500*time.Millisecond)
+	// after the loop, the tracer should report to be in sync with the chain head
+	require.Equal(t, SyncStatus{
+		LastProcessedBlock: 22391066,
+		ChainHead:          22391066,
+		Lag:                0,
+		CatchingUp:         false,
+	}, tracer.SyncStatus())
+}
+
+// TestCatchUpCancelled() tests that catching up stops as soon as the context is done,
+// without any further calls to the chain or the storage
+func TestCatchUpCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// no calls are expected on either mock
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	mockRPCClient := mocks.NewMockRPCClient(ctrl)
+	tracer := NewBlockTracer(mockRPCClient, mockStorage, getTestMetrics(t), common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	tracer.catchUp(ctx, 22391064, 22391066)
+	require.Equal(t, uint64(0), tracer.SyncStatus().LastProcessedBlock)
+}
+
+// TestCatchUpSaveFailed() tests that a block which couldn't be saved doesn't hold back the sync status
+func TestCatchUpSaveFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	mockRPCClient := mocks.NewMockRPCClient(ctrl)
+	r1 := mockRPCClient.EXPECT().Call(gomock.Any(), TraceBlockRPC, gomock.Any()).Return(getJSON(t, "./testdata/trace_block.json"), nil)
+	r2 := mockRPCClient.EXPECT().Call(gomock.Any(), BlockByHashRPC, gomock.Any()).After(r1).Return(getJSON(t, "./testdata/block_hash.json"), nil)
+	mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any(), gomock.Any()).After(r2).Return(database.ErrUnavailable)
+
+	tracer := NewBlockTracer(mockRPCClient, mockStorage, getTestMetrics(t), common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+	tracer.chainHead.Store(22391066)
+	tracer.catchUp(t.Context(), 22391066, 22391066)
+	require.Equal(t, uint64(22391066), tracer.SyncStatus().LastProcessedBlock)
+	require.Equal(t, uint64(0), tracer.SyncStatus().Lag)
+}
+
+// TestStopWait() tests that Stop aborts catching up after the block being processed,
+// and Wait returns once Start returned
+func TestStopWait(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	mockRPCClient := mocks.NewMockRPCClient(ctrl)
+	tracer := NewBlockTracer(mockRPCClient, mockStorage, getTestMetrics(t), common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+
+	// the tracer needs to catch up 3 blocks, but is stopped while saving the first one
+	s1 := mockStorage.EXPECT().LatestBlock(gomock.Any()).Return(uint64(22391064), nil)
+	mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any(), gomock.Any()).After(s1).
+		DoAndReturn(func(ctx context.Context, _ *database.MEVBlock, _ []*database.MEVTransaction) error {
+			tracer.Stop()
+			// the save is aborted with the context
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+	r1 := mockRPCClient.EXPECT().Call(gomock.Any(), LastBlockRPC, nil).Return(getJSON(t, "./testdata/block_number.json"), nil)
+	r2 := mockRPCClient.EXPECT().Call(gomock.Any(), TraceBlockRPC, gomock.Any()).After(r1).Return(getJSON(t, "./testdata/trace_block.json"), nil)
+	mockRPCClient.EXPECT().Call(gomock.Any(), BlockByHashRPC, gomock.Any()).After(r2).Return(getJSON(t, "./testdata/block_hash.json"), nil)
+
+	go tracer.Start(t.Context(), 10*time.Millisecond)
+	tracer.Wait()
+	require.True(t, tracer.SyncStatus().CatchingUp)
+	require.Equal(t, uint64(0), tracer.SyncStatus().LastProcessedBlock)
+
+	// a stopped tracer doesn't start again
+	tracer = NewBlockTracer(mockRPCClient, mockStorage, getTestMetrics(t), common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+	tracer.Stop()
+	tracer.Start(t.Context(), 10*time.Millisecond)
+	tracer.Wait()
+}
+
+// TestBackfill() tests that backfilling skips stored blocks, traces missing ones
+// and stops if the storage fails
+func TestBackfill(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	mockRPCClient := mocks.NewMockRPCClient(ctrl)
+	// the first block is already stored...
+	s1 := mockStorage.EXPECT().GetMEVBlock(gomock.Any(), "22391064").Return(&database.MEVBlock{}, nil)
+	// ...the second one is traced and saved...
+	s2 := mockStorage.EXPECT().GetMEVBlock(gomock.Any(), "22391065").After(s1).Return(nil, database.ErrNotFound)
+	r1 := mockRPCClient.EXPECT().Call(gomock.Any(), TraceBlockRPC, "0x155a919").After(s2).Return(getJSON(t, "./testdata/trace_block.json"), nil)
+	r2 := mockRPCClient.EXPECT().Call(gomock.Any(), BlockByHashRPC, gomock.Any()).After(r1).Return(getJSON(t, "./testdata/block_hash.json"), nil)
+	s3 := mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any(), gomock.Any()).After(r2).Return(nil)
+	// ...and then the storage fails
+	mockStorage.EXPECT().GetMEVBlock(gomock.Any(), "22391066").After(s3).Return(nil, database.ErrUnavailable)
+
+	tracer := NewBlockTracer(mockRPCClient, mockStorage, getTestMetrics(t), common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+	failed, err := tracer.Backfill(t.Context(), 22391064, 22391067)
+	require.ErrorIs(t, err, database.ErrUnavailable)
+	require.Equal(t, uint64(0), failed)
+}
+
+// TestBackfillValueStored() tests that backfilling values the stored blocks without USD values
+func TestBackfillValueStored(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	tracer := NewBlockTracer(mocks.NewMockRPCClient(ctrl), mockStorage, getTestMetrics(t), common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+	var err error
+	tracer.Prices, err = prices.ReadCSV(strings.NewReader("2024-10-29,2500\n"))
+	require.NoError(t, err)
+	tracer.ValueStored = true
+
+	// 0.5 ETH on 2024-10-29
+	unpriced := &database.MEVBlock{
+		BlockNumber:     22391064,
+		TotalMinerValue: big.NewInt(5e17),
+		Timestamp:       0x6720e0c0,
+		MEVTransactions: []*database.MEVTransaction{{Value: big.NewInt(5e17)}},
+	}
+	price := 2500.0
+	priced := &database.MEVBlock{BlockNumber: 22391065, Timestamp: 0x6720e0c0, ETHUSD: &price}
+	// a block after the last price can't be valued
+	late := &database.MEVBlock{BlockNumber: 22391066, TotalMinerValue: big.NewInt(1), Timestamp: 0x6920e0c0}
+	s1 := mockStorage.EXPECT().GetMEVBlock(gomock.Any(), "22391064").Return(unpriced, nil)
+	s2 := mockStorage.EXPECT().SaveUSDValues(gomock.Any(), unpriced).After(s1).
+		DoAndReturn(func(_ context.Context, block *database.MEVBlock) error {
+			require.InDelta(t, 2500, *block.ETHUSD, 1e-9)
+			require.InDelta(t, 1250, *block.TotalMinerValueUSD, 1e-9)
+			require.InDelta(t, 1250, *block.MEVTransactions[0].ValueUSD, 1e-9)
+			return nil
+		})
+	s3 := mockStorage.EXPECT().GetMEVBlock(gomock.Any(), "22391065").After(s2).Return(priced, nil)
+	mockStorage.EXPECT().GetMEVBlock(gomock.Any(), "22391066").After(s3).Return(late, nil)
+
+	failed, err := tracer.Backfill(t.Context(), 22391064, 22391066)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), failed)
+}
+
+// fakeElector elects the tracer for a single term, which ends once elected is closed
+type fakeElector struct {
+	elected chan struct{}
+	ended   chan struct{}
+}
+
+func (e *fakeElector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-e.elected:
+	}
+	leaderCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		<-e.ended
+		cancel()
+	}()
+	lead(leaderCtx)
+	<-ctx.Done()
+}
+
+// TestLeaderElection() tests that a tracer with an elector only indexes while leader
+func TestLeaderElection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	mockRPCClient := mocks.NewMockRPCClient(ctrl)
+	tracer := NewBlockTracer(mockRPCClient, mockStorage, getTestMetrics(t), common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+	elector := &fakeElector{elected: make(chan struct{}), ended: make(chan struct{})}
+	tracer.SetElector(elector)
+	go tracer.Start(t.Context(), 10*time.Millisecond)
+
+	// no calls are expected while standing by
+	time.Sleep(50 * time.Millisecond)
+	require.True(t, tracer.SyncStatus().Standby)
+
+	// once elected, the tracer indexes; we end the term after the first poll
+	polled := make(chan struct{})
+	mockStorage.EXPECT().LatestBlock(gomock.Any()).Return(uint64(22391066), nil)
+	mockRPCClient.EXPECT().Call(gomock.Any(), LastBlockRPC, nil).
+		DoAndReturn(func(context.Context, string, ...any) (*rpcclient.RPCResponse, error) {
+			close(polled)
+			return getJSON(t, "./testdata/block_number.json"), nil
+		})
+	close(elector.elected)
+	<-polled
+	require.False(t, tracer.SyncStatus().Standby)
+
+	close(elector.ended)
+	require.Eventually(t, func() bool { return tracer.SyncStatus().Standby }, time.Second, 10*time.Millisecond)
+	tracer.Stop()
+	tracer.Wait()
 }
 
 func TestMissingBlock(t *testing.T) {
@@ -80,13 +305,13 @@ func TestMissingBlock(t *testing.T) {
 	// The test loads a fixed json testdata file, which has the latest block set to 2391066
 	// This time we skip one, simulating a missing block.
 	// Logic should not error and continue
-	s1 := mockStorage.EXPECT().LatestBlock().Return(uint64(22391064), nil)
+	s1 := mockStorage.EXPECT().LatestBlock(gomock.Any()).Return(uint64(22391064), nil)
 	// ...so then we save 2 blocks this time...(it's actually irrelevant, as we aren't really saving)
-	s2 := mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any()).After(s1).Return(nil)
-	s4 := mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any()).After(s2).Return(nil)
+	s2 := mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any(), gomock.Any()).After(s1).Return(nil)
+	s4 := mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any(), gomock.Any()).After(s2).Return(nil)
 	// ...after which the loop will call for the latest block again.
 	// THIS IS THE SIGNAL THAT EVERYTHING WENT WELL, so we call the cancel function of the ctx, which will stop the loop and finish the test
-	mockStorage.EXPECT().LatestBlock().After(s4).Return(uint64(22391066), nil).Do(cancel)
+	mockStorage.EXPECT().LatestBlock(gomock.Any()).After(s4).Return(uint64(22391066), nil).Do(func(context.Context) { cancel() })
 
 	// create a mock instance for the RPC client
 	mockRPCClient := mocks.NewMockRPCClient(ctrl)
@@ -111,11 +336,149 @@ func TestMissingBlock(t *testing.T) {
 		Service: "test",
 		Version: common.Version,
 	})
-	tracer := NewBlockTracer(mockRPCClient, mockStorage, log)
+	tracer := NewBlockTracer(mockRPCClient, mockStorage, getTestMetrics(t), log)
 	// Here is where the test actually starts!
 	tracer.Start(ctx, 500*time.Millisecond)
 }
 
+// TestHandleTxsPosition() tests that the position of the coinbase transfers is stored
+func TestHandleTxsPosition(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	tracer := NewBlockTracer(mocks.NewMockRPCClient(ctrl), mockStorage, getTestMetrics(t), common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+
+	const miner = "0x7f101fe45e6649a6fb8f3f8b43ed03d353f2b90c"
+	trace := TraceBlockResponse{
+		{
+			Action:              Action{From: "0x1111", To: miner, Value: "0x2a"},
+			Result:              Result{GasUsed: "0x5208"},
+			TraceAddress:        []uint64{},
+			TransactionHash:     "0xaa",
+			TransactionPosition: 0,
+		},
+		{
+			Action:              Action{From: "0x2222", To: "0x3333", Value: "0x1"},
+			TransactionHash:     "0xbb",
+			TransactionPosition: 1,
+		},
+		{
+			Action:              Action{From: "0x4444", To: miner, Value: "0x10"},
+			TraceAddress:        []uint64{1, 0},
+			TransactionHash:     "0xcc",
+			TransactionPosition: 7,
+		},
+	}
+	mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, block *database.MEVBlock, txs []*database.MEVTransaction) error {
+			require.Equal(t, "58", block.TotalMinerValue.String())
+			require.Len(t, txs, 2)
+			require.Equal(t, uint64(0), txs[0].Position)
+			require.Empty(t, txs[0].TraceAddress)
+			require.Equal(t, uint64(21_000), txs[0].GasUsed)
+			// traces without a result have no gas used
+			require.Equal(t, uint64(7), txs[1].Position)
+			require.Equal(t, []uint64{1, 0}, txs[1].TraceAddress)
+			require.Equal(t, uint64(0), txs[1].GasUsed)
+			return nil
+		})
+
+	block := &Block{Miner: miner, Timestamp: "0x6720e0c0"}
+	require.NoError(t, tracer.handleTxs(t.Context(), &trace, block, nil, "0x1234", 22391065))
+}
+
+// TestHandleTxsMixedCase() tests that transfers to the miner are found whatever the case the node returns
+func TestHandleTxsMixedCase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	tracer := NewBlockTracer(mocks.NewMockRPCClient(ctrl), mockStorage, getTestMetrics(t), common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+
+	var trace TraceBlockResponse
+	require.NoError(t, json.Unmarshal([]byte(`[{
+		"action": {"from": "0x1111", "to": "0x7f101fe45e6649a6fb8f3f8b43ed03d353f2b90c", "value": "0x2a"},
+		"transactionHash": "0xAA"
+	}]`), &trace))
+	var block Block
+	require.NoError(t, json.Unmarshal([]byte(`{"miner": "0x7F101fE45e6649A6fB8F3F8B43ed03D353f2B90c", "timestamp": "0x6720e0c0"}`), &block))
+
+	mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, block *database.MEVBlock, txs []*database.MEVTransaction) error {
+			require.Equal(t, common.Address("0x7f101fe45e6649a6fb8f3f8b43ed03d353f2b90c"), block.Miner)
+			require.Len(t, txs, 1)
+			require.Equal(t, common.Hash("0xaa"), txs[0].TXHash)
+			return nil
+		})
+	require.NoError(t, tracer.handleTxs(t.Context(), &trace, &block, nil, "0x1234", 22391065))
+}
+
+// TestHandleTxsMalformedTimestamp() tests that a block with a malformed timestamp is stored without it, with a warning
+func TestHandleTxsMalformedTimestamp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	var logs bytes.Buffer
+	tracer := NewBlockTracer(mocks.NewMockRPCClient(ctrl), mockStorage, getTestMetrics(t), slog.New(slog.NewTextHandler(&logs, nil)))
+
+	const miner = "0x7f101fe45e6649a6fb8f3f8b43ed03d353f2b90c"
+	trace := TraceBlockResponse{{Action: Action{From: "0x1111", To: miner, Value: "0x2a"}, TransactionHash: "0xaa"}}
+	for _, timestamp := range []string{"", "0xzz", "0x1ffffffffffffffff"} {
+		logs.Reset()
+		mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, block *database.MEVBlock, _ []*database.MEVTransaction) error {
+				require.Equal(t, uint64(0), block.Timestamp, timestamp)
+				return nil
+			})
+		require.NoError(t, tracer.handleTxs(t.Context(), &trace, &Block{Miner: miner, Timestamp: timestamp}, nil, "0x1234", 22391065))
+		require.Contains(t, logs.String(), "level=WARN msg=\"failed to parse block timestamp", timestamp)
+	}
+}
+
+// TestHandleTxsUSD() tests that blocks and transfers are valued at the price of the block's time
+func TestHandleTxsUSD(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	tracer := NewBlockTracer(mocks.NewMockRPCClient(ctrl), mockStorage, getTestMetrics(t), common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+	var err error
+	// 0x6720e0c0 is 2024-10-29 13:18:56 UTC
+	tracer.Prices, err = prices.ReadCSV(strings.NewReader("2024-10-29,2500\n"))
+	require.NoError(t, err)
+
+	const miner = "0x7f101fe45e6649a6fb8f3f8b43ed03d353f2b90c"
+	trace := TraceBlockResponse{
+		{Action: Action{From: "0x1111", To: miner, Value: "0x6f05b59d3b20000"}, TransactionHash: "0xaa"},
+		{Action: Action{From: "0x2222", To: miner, Value: "0x14d1120d7b160000"}, TransactionHash: "0xbb"},
+	}
+	mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, block *database.MEVBlock, txs []*database.MEVTransaction) error {
+			// 0.5 and 1.5 ETH
+			require.InDelta(t, 2500, *block.ETHUSD, 1e-9)
+			require.InDelta(t, 5000, *block.TotalMinerValueUSD, 1e-9)
+			require.InDelta(t, 1250, *txs[0].ValueUSD, 1e-9)
+			require.InDelta(t, 3750, *txs[1].ValueUSD, 1e-9)
+			return nil
+		})
+	block := &Block{Miner: miner, Timestamp: "0x6720e0c0"}
+	require.NoError(t, tracer.handleTxs(t.Context(), &trace, block, nil, "0x1234", 22391065))
+
+	// without a price, the block is stored without USD values
+	block.Timestamp = "0x6920e0c0"
+	mockStorage.EXPECT().SaveMEVBLock(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, block *database.MEVBlock, txs []*database.MEVTransaction) error {
+			require.Nil(t, block.ETHUSD)
+			require.Nil(t, block.TotalMinerValueUSD)
+			require.Nil(t, txs[0].ValueUSD)
+			return nil
+		})
+	require.NoError(t, tracer.handleTxs(t.Context(), &trace, block, nil, "0x1234", 22391066))
+}
+
 // TestTraceBlockJSONParse() just tests that we can parse the json response from trace_block
 func TestTraceBlockJSONParse(t *testing.T) {
 	var btr TraceBlockResponse
@@ -127,10 +490,18 @@ func TestTraceBlockJSONParse(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, btr, 989)
 	for _, b := range btr {
-		require.Equal(t, "0x4c2707c769754fe23764a765fc8d50a5fa4172a670e46671dd3e1c0c34036dfe", b.BlockHash)
+		require.Equal(t, common.Hash("0x4c2707c769754fe23764a765fc8d50a5fa4172a670e46671dd3e1c0c34036dfe"), b.BlockHash)
 	}
 }
 
+// getTestMetrics() creates a metrics server which is not listening
+func getTestMetrics(t *testing.T) *metrics.MetricsServer {
+	t.Helper()
+	metricsSrv, err := metrics.New(common.PackageName, "")
+	require.NoError(t, err)
+	return metricsSrv
+}
+
 // getJSONResult() gets the "result" part as a json.RawMessage from a JSON response
 func getJSONResult(t *testing.T, filename string) json.RawMessage {
 	t.Helper()
@@ -0,0 +1,100 @@
+package blocktrace
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database"
+)
+
+// touchedContracts returns the contract a transaction called and the pools it swapped on.
+// Other event emitters are left out: token contracts like WETH emit events in many unrelated txs.
+func touchedContracts(r *Receipt) []common.Address {
+	contracts := []common.Address{}
+	if r.To != "" {
+		contracts = append(contracts, r.To)
+	}
+	for i := range r.Logs {
+		l := &r.Logs[i]
+		if len(l.Topics) == 0 {
+			continue
+		}
+		switch strings.ToLower(l.Topics[0]) {
+		case UniswapV2SwapTopic, UniswapV3SwapTopic:
+			contracts = append(contracts, l.Address)
+		}
+	}
+	return contracts
+}
+
+// detectBundles groups the transactions paying the coinbase with the consecutive transactions before them
+// sharing a sender, a called contract or a pool traded on with the group.
+// This is a heuristic: bundles are not visible on chain. Groups of a single tx are not bundles,
+// and a tx is part of at most one bundle.
+func detectBundles(blockNumber uint64, receipts []*Receipt, txs []*database.MEVTransaction) []*database.Bundle {
+	bribes, _ := coinbaseBribes(txs)
+	byPosition := map[uint64]*Receipt{}
+	paying := []uint64{}
+	for _, r := range receipts {
+		position := parseHexUint(r.TransactionIndex)
+		byPosition[position] = r
+		if _, ok := bribes[r.TransactionHash]; ok {
+			paying = append(paying, position)
+		}
+	}
+
+	bundles := []*database.Bundle{}
+	claimed := map[uint64]bool{}
+	for _, end := range paying {
+		if claimed[end] {
+			continue
+		}
+		senders := map[common.Address]bool{}
+		contracts := map[common.Address]bool{}
+		add := func(r *Receipt) {
+			senders[r.From] = true
+			for _, c := range touchedContracts(r) {
+				contracts[c] = true
+			}
+		}
+		add(byPosition[end])
+		start := end
+		for start > 0 && !claimed[start-1] {
+			prev, ok := byPosition[start-1]
+			if !ok || !related(prev, senders, contracts) {
+				break
+			}
+			add(prev)
+			start--
+		}
+		if start == end {
+			continue
+		}
+
+		bundle := &database.Bundle{BlockNumber: blockNumber, Position: start, Payment: new(big.Int)}
+		for p := start; p <= end; p++ {
+			hash := byPosition[p].TransactionHash
+			bundle.TxHashes = append(bundle.TxHashes, hash)
+			if bribe, ok := bribes[hash]; ok {
+				bundle.Payment.Add(bundle.Payment, bribe)
+			}
+			claimed[p] = true
+		}
+		bundles = append(bundles, bundle)
+	}
+	return bundles
+}
+
+// related is true if r was sent by one of senders or touched one of contracts
+func related(r *Receipt, senders, contracts map[common.Address]bool) bool {
+	if senders[r.From] {
+		return true
+	}
+	for _, c := range touchedContracts(r) {
+		if contracts[c] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package blocktrace
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/stretchr/testify/require"
+)
+
+// bundleReceipt() creates the receipt of a tx at position emitting an event with topic from each of emitters
+func bundleReceipt(position uint64, from, to common.Address, topic string, emitters ...common.Address) *Receipt {
+	r := &Receipt{
+		TransactionHash:  common.Hash(fmt.Sprintf("0x%02x", position)),
+		TransactionIndex: fmt.Sprintf("0x%x", position),
+		From:             from,
+		To:               to,
+		Status:           "0x1",
+	}
+	for _, address := range emitters {
+		r.Logs = append(r.Logs, Log{Address: address, Topics: []string{topic}})
+	}
+	return r
+}
+
+// TestDetectBundles() tests grouping the txs before a coinbase payment by shared senders and contracts
+func TestDetectBundles(t *testing.T) {
+	const (
+		searcher common.Address = "0x5555555555555555555555555555555555555555"
+		router   common.Address = "0x6666666666666666666666666666666666666666"
+		other    common.Address = "0x7777777777777777777777777777777777777777"
+	)
+	receipts := []*Receipt{
+		bundleReceipt(0, "0x8888888888888888888888888888888888888888", "0x9999999999999999999999999999999999999999", ""),
+		// searcher front-runs on the pool
+		bundleReceipt(1, searcher, router, UniswapV2SwapTopic, testPool),
+		// victim trades on the same pool, through another contract
+		bundleReceipt(2, testVictim, other, UniswapV2SwapTopic, testPool),
+		// searcher back-runs and pays the coinbase
+		bundleReceipt(3, searcher, router, UniswapV2SwapTopic, testPool),
+		// unrelated txs, one paying the coinbase on its own
+		bundleReceipt(4, testAttacker, other, ""),
+		bundleReceipt(5, testVictim, testToken0, ""),
+		// a bundle of the same sender, paying in both txs
+		bundleReceipt(6, testAttacker, testToken1, ""),
+		bundleReceipt(7, testAttacker, testToken1, ""),
+	}
+	txs := []*database.MEVTransaction{
+		{TXHash: "0x03", Value: big.NewInt(30)},
+		{TXHash: "0x03", Value: big.NewInt(12), TraceAddress: []uint64{0}},
+		{TXHash: "0x05", Value: big.NewInt(1)},
+		{TXHash: "0x06", Value: big.NewInt(2)},
+		{TXHash: "0x07", Value: big.NewInt(3)},
+	}
+	require.Equal(t, []*database.Bundle{
+		{BlockNumber: 21_000_042, Position: 1, TxHashes: []common.Hash{"0x01", "0x02", "0x03"}, Payment: big.NewInt(42)},
+		{BlockNumber: 21_000_042, Position: 6, TxHashes: []common.Hash{"0x06", "0x07"}, Payment: big.NewInt(5)},
+	}, detectBundles(21_000_042, receipts, txs))
+}
+
+// TestDetectBundlesSharedToken() tests that txs are not grouped by a token contract emitting events in both
+func TestDetectBundlesSharedToken(t *testing.T) {
+	receipts := []*Receipt{
+		// two unrelated senders, both moving WETH through different contracts
+		bundleReceipt(0, testVictim, testToken0, TransferTopic, WETHAddress),
+		bundleReceipt(1, testAttacker, testToken1, TransferTopic, WETHAddress),
+	}
+	txs := []*database.MEVTransaction{
+		{TXHash: "0x01", Value: big.NewInt(1)},
+	}
+	require.Empty(t, detectBundles(21_000_042, receipts, txs))
+}
+
+// TestDetectBundlesMixedCase() tests that receipts in mixed case are matched with the lower case coinbase payments
+func TestDetectBundlesMixedCase(t *testing.T) {
+	var receipts []*Receipt
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"transactionHash": "0xAA", "transactionIndex": "0x0", "status": "0x1",
+		 "from": "0x5555555555555555555555555555555555555555", "to": "0x6666666666666666666666666666666666666666"},
+		{"transactionHash": "0xBB", "transactionIndex": "0x1", "status": "0x1",
+		 "from": "0x5555555555555555555555555555555555555555", "to": "0x6666666666666666666666666666666666666666"}
+	]`), &receipts))
+	txs := []*database.MEVTransaction{
+		{TXHash: "0xbb", Value: big.NewInt(42)},
+	}
+	require.Equal(t, []*database.Bundle{
+		{BlockNumber: 21_000_042, Position: 0, TxHashes: []common.Hash{"0xaa", "0xbb"}, Payment: big.NewInt(42)},
+	}, detectBundles(21_000_042, receipts, txs))
+}
@@ -0,0 +1,77 @@
+package blocktrace
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/holisticode/mev-rpc/database"
+)
+
+// topics of the liquidation events of the supported lending protocols
+const (
+	// LiquidationCall(address indexed collateralAsset, address indexed debtAsset, address indexed user,
+	// uint256 debtToCover, uint256 liquidatedCollateralAmount, address liquidator, bool receiveAToken)
+	AaveLiquidationCallTopic = "0xe413a321e8681d831f4dbccbca790d2952b56f977908e45be37335533e005286"
+	// LiquidateBorrow(address liquidator, address borrower, uint256 repayAmount, address cTokenCollateral, uint256 seizeTokens)
+	CompoundLiquidateBorrowTopic = "0x298637f684da70674f26509b10f07ec2fbc77a335ab1e7d6215a4b2484d8bb52"
+)
+
+// names of the supported lending protocols
+const (
+	ProtocolAave     = "aave"
+	ProtocolCompound = "compound"
+)
+
+// decodeLiquidations extracts the liquidations of successful transactions, in block order.
+// Aave V2 and V3 emit the same event; Compound's is emitted by the cToken of the repaid debt,
+// and its collateral is a cToken, seized in cToken units.
+func decodeLiquidations(blockNumber uint64, receipts []*Receipt) []*database.Liquidation {
+	liquidations := []*database.Liquidation{}
+	for _, r := range receipts {
+		if !receiptSucceeded(r) {
+			continue
+		}
+		position := parseHexUint(r.TransactionIndex)
+		for i := range r.Logs {
+			l := &r.Logs[i]
+			if len(l.Topics) == 0 {
+				continue
+			}
+			liq := &database.Liquidation{
+				BlockNumber: blockNumber,
+				TxHash:      r.TransactionHash,
+				Position:    position,
+				LogIndex:    parseHexUint(l.LogIndex),
+			}
+			words := dataWords(l.Data)
+			switch strings.ToLower(l.Topics[0]) {
+			case AaveLiquidationCallTopic:
+				if len(l.Topics) < 4 || len(words) < 3 {
+					continue
+				}
+				liq.Protocol = ProtocolAave
+				liq.CollateralAsset = wordAddress(l.Topics[1])
+				liq.DebtAsset = wordAddress(l.Topics[2])
+				liq.Borrower = wordAddress(l.Topics[3])
+				liq.RepaidDebt = new(big.Int).SetBytes(words[0])
+				liq.SeizedCollateral = new(big.Int).SetBytes(words[1])
+				liq.Liquidator = dataAddress(words[2])
+			case CompoundLiquidateBorrowTopic:
+				if len(words) < 5 {
+					continue
+				}
+				liq.Protocol = ProtocolCompound
+				liq.Liquidator = dataAddress(words[0])
+				liq.Borrower = dataAddress(words[1])
+				liq.RepaidDebt = new(big.Int).SetBytes(words[2])
+				liq.CollateralAsset = dataAddress(words[3])
+				liq.SeizedCollateral = new(big.Int).SetBytes(words[4])
+				liq.DebtAsset = l.Address
+			default:
+				continue
+			}
+			liquidations = append(liquidations, liq)
+		}
+	}
+	return liquidations
+}
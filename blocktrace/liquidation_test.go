@@ -0,0 +1,100 @@
+package blocktrace
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/holisticode/mev-rpc/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testLiquidator = "0x2222222222222222222222222222222222222222"
+	testCToken     = "0x39aa39c021dfbae8fac545936693ac917d5e7563"
+	testCTokenColl = "0x4ddc2d193948926d02f9b1fe9e1daa0718270ed5"
+)
+
+// topic pads an address to a 32 byte topic
+func topic(address common.Address) string {
+	return "0x000000000000000000000000" + string(address[2:])
+}
+
+// TestDecodeLiquidations() tests decoding Aave and Compound liquidations from receipts
+func TestDecodeLiquidations(t *testing.T) {
+	aave := &Receipt{
+		TransactionHash:  "0x01",
+		TransactionIndex: "0x1",
+		Status:           "0x1",
+		Logs: []Log{{
+			Address: "0x87870bca3f3fd6335c3f4ce8392d69350b4fa4e2",
+			Topics:  []string{AaveLiquidationCallTopic, topic(testToken1), topic(testToken0), topic(testVictim)},
+			// debtToCover, liquidatedCollateralAmount, liquidator, receiveAToken
+			Data:     encodeWords(5000, 3) + topic(testLiquidator)[2:] + encodeWords(0)[2:],
+			LogIndex: "0x7",
+		}},
+	}
+	compound := &Receipt{
+		TransactionHash:  "0x02",
+		TransactionIndex: "0x2",
+		Status:           "0x1",
+		Logs: []Log{{
+			Address: testCToken,
+			Topics:  []string{CompoundLiquidateBorrowTopic},
+			// liquidator, borrower, repayAmount, cTokenCollateral, seizeTokens
+			Data: HexPrefix + topic(testLiquidator)[2:] + topic(testVictim)[2:] + encodeWords(1000)[2:] +
+				topic(testCTokenColl)[2:] + encodeWords(42)[2:],
+			LogIndex: "0x1",
+		}},
+	}
+	reverted := &Receipt{TransactionHash: "0x03", Status: "0x0", Logs: compound.Logs}
+
+	require.Equal(t, []*database.Liquidation{
+		{
+			BlockNumber: 21_000_042, TxHash: "0x01", Position: 1, LogIndex: 7, Protocol: ProtocolAave,
+			Liquidator: testLiquidator, Borrower: testVictim,
+			CollateralAsset: testToken1, SeizedCollateral: big.NewInt(3),
+			DebtAsset: testToken0, RepaidDebt: big.NewInt(5000),
+		},
+		{
+			BlockNumber: 21_000_042, TxHash: "0x02", Position: 2, LogIndex: 1, Protocol: ProtocolCompound,
+			Liquidator: testLiquidator, Borrower: testVictim,
+			CollateralAsset: testCTokenColl, SeizedCollateral: big.NewInt(42),
+			DebtAsset: testCToken, RepaidDebt: big.NewInt(1000),
+		},
+	}, decodeLiquidations(21_000_042, []*Receipt{aave, compound, reverted}))
+}
+
+// TestAnalyzeLiquidations() tests that liquidations are linked to the coinbase payments of their tx
+func TestAnalyzeLiquidations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	tracer := NewBlockTracer(mocks.NewMockRPCClient(ctrl), mocks.NewMockMEVTraceStorage(ctrl), getTestMetrics(t), common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+
+	receipt := func(hash common.Hash) *Receipt {
+		return &Receipt{
+			TransactionHash: hash,
+			Status:          "0x1",
+			Logs: []Log{{
+				Address: testCToken,
+				Topics:  []string{CompoundLiquidateBorrowTopic},
+				Data: HexPrefix + topic(testLiquidator)[2:] + topic(testVictim)[2:] + encodeWords(1000)[2:] +
+					topic(testCTokenColl)[2:] + encodeWords(42)[2:],
+			}},
+		}
+	}
+	txs := []*database.MEVTransaction{
+		{TXHash: "0x01", Value: big.NewInt(30)},
+		{TXHash: "0x01", Value: big.NewInt(12), TraceAddress: []uint64{0}},
+	}
+	mevBlock := &database.MEVBlock{BlockNumber: 21_000_042}
+	tracer.analyzeBlock(t.Context(), []*Receipt{receipt("0x01"), receipt("0x02")}, mevBlock, txs)
+
+	require.Len(t, mevBlock.Liquidations, 2)
+	require.Equal(t, big.NewInt(42), mevBlock.Liquidations[0].Bribe)
+	require.Nil(t, mevBlock.Liquidations[1].Bribe)
+}
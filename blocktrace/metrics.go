@@ -0,0 +1,173 @@
+package blocktrace
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/flashbots/go-utils/rpcclient"
+	"github.com/holisticode/mev-rpc/metrics"
+	"github.com/holisticode/mev-rpc/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// metric names exported by the tracer
+const (
+	MetricBlocksProcessed   = "tracer_blocks_processed"
+	MetricBlocksSkipped     = "tracer_blocks_skipped"
+	MetricRPCDuration       = "tracer_rpc_duration"
+	MetricRPCErrors         = "tracer_rpc_errors"
+	MetricStorageErrors     = "tracer_storage_errors"
+	MetricHeadLag           = "tracer_head_lag"
+	MetricCoinbaseTransfers = "tracer_coinbase_transfers"
+	MetricMEVValue          = "tracer_mev_value"
+	MetricLeader            = "tracer_leader"
+	MetricMEVDetected       = "tracer_mev_detected"
+)
+
+// reasons for which a block can be skipped, used as metric label
+const (
+	SkipReasonTraceFailed    = "trace_failed"
+	SkipReasonEmptyBlock     = "empty_block"
+	SkipReasonGetBlockFailed = "get_block_failed"
+	SkipReasonSaveFailed     = "save_failed"
+)
+
+// registerGauges registers the gauges which are read on collection
+func (t *Tracer) registerGauges() {
+	if t.metrics == nil {
+		return
+	}
+	t.metrics.Int64Gauge(
+		MetricHeadLag,
+		"number of blocks the tracer is behind the chain head",
+		metrics.UomBlocks,
+		func() int64 { return int64(t.SyncStatus().Lag) }, //nolint:gosec
+	)
+	t.metrics.Int64Gauge(
+		MetricLeader,
+		"1 if this tracer is indexing (elected leader), 0 if it is standing by",
+		"",
+		func() int64 {
+			if t.leading.Load() {
+				return 1
+			}
+			return 0
+		},
+	)
+}
+
+// call executes an RPC call on the chain node, recording its latency and errors
+func (t *Tracer) call(ctx context.Context, method string, params ...any) (resp *rpcclient.RPCResponse, err error) {
+	ctx, span := tracing.Start(ctx, "rpcclient "+method, trace.SpanKindClient,
+		attribute.String("rpc.system", "jsonrpc"),
+		attribute.String("rpc.method", method))
+	defer func() { tracing.End(span, err) }()
+
+	defer func(start time.Time) {
+		if t.metrics == nil {
+			return
+		}
+		t.metrics.Float64Histogram(
+			MetricRPCDuration,
+			"duration of RPC calls to the chain node",
+			metrics.UomMilliseconds,
+			metrics.BucketsRPCDuration...,
+		).Record(ctx, float64(time.Since(start).Microseconds())/1000, methodAttr(method))
+	}(time.Now())
+
+	resp, err = t.rpcClient.Call(ctx, method, params...)
+	// the node can also return an error as part of a successful call
+	if err == nil && resp != nil && resp.Error != nil {
+		err = resp.Error
+	}
+	if err != nil {
+		t.recordRPCError(ctx, method)
+	}
+	return resp, err
+}
+
+// recordRPCError counts failed RPC calls, including responses we failed to decode
+func (t *Tracer) recordRPCError(ctx context.Context, method string) {
+	if t.metrics == nil {
+		return
+	}
+	t.metrics.Int64Counter(
+		MetricRPCErrors,
+		"number of failed RPC calls to the chain node",
+		"",
+	).Add(ctx, 1, methodAttr(method))
+}
+
+// recordStorageError counts failed storage operations
+func (t *Tracer) recordStorageError(ctx context.Context) {
+	if t.metrics == nil {
+		return
+	}
+	t.metrics.Int64Counter(
+		MetricStorageErrors,
+		"number of failed storage operations",
+		"",
+	).Add(ctx, 1)
+}
+
+// recordBlockProcessed counts blocks which have been traced successfully
+func (t *Tracer) recordBlockProcessed(ctx context.Context) {
+	if t.metrics == nil {
+		return
+	}
+	t.metrics.Int64Counter(
+		MetricBlocksProcessed,
+		"number of blocks traced",
+		metrics.UomBlocks,
+	).Add(ctx, 1)
+}
+
+// recordBlockSkipped counts blocks which have been skipped, by reason
+func (t *Tracer) recordBlockSkipped(ctx context.Context, reason string) {
+	if t.metrics == nil {
+		return
+	}
+	t.metrics.Int64Counter(
+		MetricBlocksSkipped,
+		"number of blocks skipped",
+		metrics.UomBlocks,
+	).Add(ctx, 1, metric.WithAttributes(attribute.String("reason", reason)))
+}
+
+// recordMEV counts coinbase transfers and their value once they are stored
+func (t *Tracer) recordMEV(ctx context.Context, transfers int, value *big.Int) {
+	if t.metrics == nil {
+		return
+	}
+	t.metrics.Int64Counter(
+		MetricCoinbaseTransfers,
+		"number of coinbase transfers recorded",
+		"",
+	).Add(ctx, int64(transfers))
+	// wei amounts overflow int64, therefore we use a float counter
+	wei, _ := new(big.Float).SetInt(value).Float64()
+	t.metrics.Float64Counter(
+		MetricMEVValue,
+		"value of coinbase transfers recorded",
+		metrics.UomWei,
+	).Add(ctx, wei)
+}
+
+func methodAttr(method string) metric.MeasurementOption {
+	return metric.WithAttributes(attribute.String("method", method))
+}
+
+// recordDetected counts the MEV detected by analyzing blocks, by kind
+func (t *Tracer) recordDetected(ctx context.Context, kind string, n int) {
+	if n == 0 || t.metrics == nil {
+		return
+	}
+	t.metrics.Int64Counter(
+		MetricMEVDetected,
+		"number of MEV occurrences detected, e.g. sandwiches",
+		"",
+	).Add(ctx, int64(n), metric.WithAttributes(attribute.String("kind", kind)))
+}
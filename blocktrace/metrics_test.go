@@ -0,0 +1,74 @@
+package blocktrace
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMetricNames() tests the names the tracer's metrics are scraped with, as documented in the README
+func TestMetricNames(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRPCClient := mocks.NewMockRPCClient(ctrl)
+	mockRPCClient.EXPECT().Call(gomock.Any(), LastBlockRPC).Return(nil, errors.New("mocking error"))
+	metricsSrv := getTestMetrics(t)
+	tracer := NewBlockTracer(mockRPCClient, mocks.NewMockMEVTraceStorage(ctrl), metricsSrv, common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+
+	_, err := tracer.call(t.Context(), LastBlockRPC)
+	require.Error(t, err)
+	tracer.recordBlockProcessed(t.Context())
+	tracer.recordBlockSkipped(t.Context(), SkipReasonEmptyBlock)
+	tracer.recordStorageError(t.Context())
+	tracer.recordMEV(t.Context(), 2, big.NewInt(42))
+	tracer.recordDetected(t.Context(), "sandwich", 1)
+
+	rr := httptest.NewRecorder()
+	metricsSrv.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rr.Body.String()
+	// histograms in milliseconds get the unit as suffix
+	require.Regexp(t, `tracer_rpc_duration_milliseconds_count\{method="eth_blockNumber".*\} 1`, body)
+	require.Regexp(t, `tracer_rpc_errors_total\{method="eth_blockNumber".*\} 1`, body)
+	require.Regexp(t, `tracer_blocks_processed_total\{.*\} 1`, body)
+	require.Regexp(t, `tracer_blocks_skipped_total\{.*reason="empty_block".*\} 1`, body)
+	require.Regexp(t, `tracer_storage_errors_total\{.*\} 1`, body)
+	require.Regexp(t, `tracer_coinbase_transfers_total\{.*\} 2`, body)
+	require.Regexp(t, `tracer_mev_value_total\{.*\} 42`, body)
+	require.Regexp(t, `tracer_mev_detected_total\{kind="sandwich".*\} 1`, body)
+	require.Regexp(t, `tracer_head_lag\{.*\} 0`, body)
+	require.Regexp(t, `tracer_leader\{.*\} 0`, body)
+}
+
+// TestNilMetrics() tests that the tracer doesn't record metrics without a metrics server
+func TestNilMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRPCClient := mocks.NewMockRPCClient(ctrl)
+	mockRPCClient.EXPECT().Call(gomock.Any(), LastBlockRPC).Return(nil, errors.New("mocking error"))
+	tracer := NewBlockTracer(mockRPCClient, mocks.NewMockMEVTraceStorage(ctrl), nil, common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+
+	require.NotPanics(t, func() {
+		_, err := tracer.call(t.Context(), LastBlockRPC)
+		require.Error(t, err)
+		tracer.recordBlockProcessed(t.Context())
+		tracer.recordBlockSkipped(t.Context(), SkipReasonEmptyBlock)
+		tracer.recordStorageError(t.Context())
+		tracer.recordMEV(t.Context(), 2, big.NewInt(42))
+		tracer.recordDetected(t.Context(), "sandwich", 1)
+	})
+}
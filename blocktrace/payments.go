@@ -0,0 +1,58 @@
+package blocktrace
+
+import (
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database"
+)
+
+// TransferTopic is the topic of the ERC-20 Transfer(address indexed from, address indexed to, uint256 value) event
+const TransferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+// DecimalsSelector is the selector of the ERC-20 decimals() function
+const DecimalsSelector = "0x313ce567"
+
+// tokenDecimals caches the decimals of ERC-20 tokens, which never change
+type tokenDecimals struct {
+	mu       sync.Mutex
+	decimals map[common.Address]uint8
+}
+
+// decodeTokenPayments extracts the ERC-20 transfers to miner of successful transactions, in block order.
+// ERC-721 transfers, which have the same topic but an indexed token id, are skipped.
+func decodeTokenPayments(blockNumber uint64, miner common.Address, receipts []*Receipt) []*database.TokenPayment {
+	payments := []*database.TokenPayment{}
+	for _, r := range receipts {
+		if !receiptSucceeded(r) {
+			continue
+		}
+		position := parseHexUint(r.TransactionIndex)
+		for i := range r.Logs {
+			l := &r.Logs[i]
+			if len(l.Topics) != 3 || strings.ToLower(l.Topics[0]) != TransferTopic || wordAddress(l.Topics[2]) != miner {
+				continue
+			}
+			words := dataWords(l.Data)
+			if len(words) < 1 {
+				continue
+			}
+			amount := new(big.Int).SetBytes(words[0])
+			if amount.Sign() == 0 {
+				continue
+			}
+			payments = append(payments, &database.TokenPayment{
+				BlockNumber: blockNumber,
+				TxHash:      r.TransactionHash,
+				Position:    position,
+				LogIndex:    parseHexUint(l.LogIndex),
+				From:        wordAddress(l.Topics[1]),
+				Token:       l.Address,
+				Amount:      amount,
+			})
+		}
+	}
+	return payments
+}
@@ -0,0 +1,96 @@
+package blocktrace
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/flashbots/go-utils/rpcclient"
+	"github.com/golang/mock/gomock"
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/holisticode/mev-rpc/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const testMiner = "0x95222290dd7278aa3ddd389cc1e1d165cc4bafe5"
+
+// transferLog creates an ERC-20 Transfer log
+func transferLog(token, from, to common.Address, amount int64, logIndex string) Log {
+	return Log{
+		Address:  token,
+		Topics:   []string{TransferTopic, topic(from), topic(to)},
+		Data:     encodeWords(amount),
+		LogIndex: logIndex,
+	}
+}
+
+// TestDecodeTokenPayments() tests that only ERC-20 transfers to the miner are decoded
+func TestDecodeTokenPayments(t *testing.T) {
+	nft := transferLog(testToken0, testAttacker, testMiner, 0, "0x3")
+	nft.Topics = append(nft.Topics, topic(testVictim))
+	receipts := []*Receipt{
+		{
+			TransactionHash:  "0x01",
+			TransactionIndex: "0x1",
+			Status:           "0x1",
+			Logs: []Log{
+				transferLog(testToken1, testAttacker, testPool, 100, "0x0"),
+				transferLog(testToken1, testAttacker, "0x95222290DD7278AA3DDD389CC1E1D165CC4BAFE5", 42, "0x1"),
+				transferLog(testToken0, testAttacker, testMiner, 0, "0x2"),
+				nft,
+			},
+		},
+		{
+			TransactionHash: "0x02",
+			Status:          "0x0",
+			Logs:            []Log{transferLog(testToken1, testAttacker, testMiner, 7, "0x0")},
+		},
+	}
+	require.Equal(t, []*database.TokenPayment{{
+		BlockNumber: 21_000_042,
+		TxHash:      "0x01",
+		Position:    1,
+		LogIndex:    1,
+		From:        testAttacker,
+		Token:       testToken1,
+		Amount:      big.NewInt(42),
+	}}, decodeTokenPayments(21_000_042, testMiner, receipts))
+}
+
+// TestAnalyzeTokenPayments() tests that token decimals are resolved, cached, and payments totaled per token
+func TestAnalyzeTokenPayments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRPCClient := mocks.NewMockRPCClient(ctrl)
+	tracer := NewBlockTracer(mockRPCClient, mocks.NewMockMEVTraceStorage(ctrl), getTestMetrics(t), common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+
+	mockRPCClient.EXPECT().Call(gomock.Any(), CallRPC, map[string]string{"to": string(testToken1), "data": DecimalsSelector}, "latest").
+		Return(&rpcclient.RPCResponse{Result: encodeWords(18)}, nil).Times(1)
+	// not a token: the payment is kept without decimals
+	mockRPCClient.EXPECT().Call(gomock.Any(), CallRPC, map[string]string{"to": string(testToken0), "data": DecimalsSelector}, "latest").
+		Return(&rpcclient.RPCResponse{Result: HexPrefix}, nil).Times(2)
+
+	receipts := []*Receipt{{
+		TransactionHash: "0x01",
+		Status:          "0x1",
+		Logs: []Log{
+			transferLog(testToken1, testAttacker, testMiner, 40, "0x0"),
+			transferLog(testToken0, testAttacker, testMiner, 5, "0x1"),
+			transferLog(testToken1, testVictim, testMiner, 2, "0x2"),
+		},
+	}}
+	for range 2 {
+		mevBlock := &database.MEVBlock{BlockNumber: 21_000_042, Miner: testMiner}
+		tracer.analyzeBlock(t.Context(), receipts, mevBlock, nil)
+		require.Len(t, mevBlock.TokenPayments, 3)
+		require.Len(t, mevBlock.TokenTotals, 2)
+		require.Equal(t, testToken1, mevBlock.TokenTotals[0].Token)
+		require.Equal(t, uint8(18), *mevBlock.TokenTotals[0].Decimals)
+		require.Equal(t, big.NewInt(42), mevBlock.TokenTotals[0].Amount)
+		require.Equal(t, uint64(2), mevBlock.TokenTotals[0].Payments)
+		require.Nil(t, mevBlock.TokenTotals[1].Decimals)
+		require.Equal(t, big.NewInt(5), mevBlock.TokenTotals[1].Amount)
+	}
+}
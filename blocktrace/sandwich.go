@@ -0,0 +1,92 @@
+package blocktrace
+
+import (
+	"math/big"
+
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database"
+)
+
+// detectedSandwich is a sandwich whose profit token is still to be resolved
+type detectedSandwich struct {
+	*database.Sandwich
+	// profitInToken0 is true if the profit is in token0 of the pool, else in token1
+	profitInToken0 bool
+}
+
+// detectSandwiches finds sandwiches among the swaps of a block, which must be in block order.
+// A sandwich is a front-run swap and a back-run swap in the opposite direction on the same pool,
+// sent by the same attacker in different transactions, around at least one victim swap by
+// another sender in the same direction as the front-run.
+// Each swap is part of at most one sandwich.
+func detectSandwiches(blockNum uint64, swaps []*Swap) []*detectedSandwich {
+	pools := []common.Address{}
+	byPool := map[common.Address][]*Swap{}
+	for _, s := range swaps {
+		if _, ok := byPool[s.Pool]; !ok {
+			pools = append(pools, s.Pool)
+		}
+		byPool[s.Pool] = append(byPool[s.Pool], s)
+	}
+
+	sandwiches := []*detectedSandwich{}
+	for _, pool := range pools {
+		poolSwaps := byPool[pool]
+		used := make([]bool, len(poolSwaps))
+		for i, front := range poolSwaps {
+			if used[i] {
+				continue
+			}
+			back := -1
+			for k := i + 1; k < len(poolSwaps); k++ {
+				s := poolSwaps[k]
+				if !used[k] && s.From == front.From && s.Position > front.Position && s.ZeroForOne() != front.ZeroForOne() {
+					back = k
+					break
+				}
+			}
+			if back < 0 {
+				continue
+			}
+			victimTxs, victims := []common.Hash{}, []common.Address{}
+			for _, s := range poolSwaps[i+1 : back] {
+				if s.From != front.From && s.Position > front.Position && s.Position < poolSwaps[back].Position &&
+					s.ZeroForOne() == front.ZeroForOne() {
+					victimTxs = append(victimTxs, s.TxHash)
+					victims = append(victims, s.From)
+				}
+			}
+			if len(victimTxs) == 0 {
+				continue
+			}
+			used[i], used[back] = true, true
+			sandwiches = append(sandwiches, newSandwich(blockNum, front, poolSwaps[back], victimTxs, victims))
+		}
+	}
+	return sandwiches
+}
+
+// newSandwich computes the profit of a sandwich in the token sold in the front-run
+func newSandwich(blockNum uint64, front, back *Swap, victimTxs []common.Hash, victims []common.Address) *detectedSandwich {
+	// the front-run pays the token into the pool, the back-run gets it out again
+	paid, received := front.Amount1, back.Amount1
+	if front.ZeroForOne() {
+		paid, received = front.Amount0, back.Amount0
+	}
+	profit := new(big.Int).Neg(received)
+	profit.Sub(profit, paid)
+	return &detectedSandwich{
+		Sandwich: &database.Sandwich{
+			BlockNumber: blockNum,
+			Attacker:    front.From,
+			Pool:        front.Pool,
+			Protocol:    front.Protocol,
+			FrontRunTx:  front.TxHash,
+			BackRunTx:   back.TxHash,
+			VictimTxs:   victimTxs,
+			Victims:     victims,
+			Profit:      profit,
+		},
+		profitInToken0: front.ZeroForOne(),
+	}
+}
@@ -0,0 +1,153 @@
+package blocktrace
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/flashbots/go-utils/rpcclient"
+	"github.com/golang/mock/gomock"
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/holisticode/mev-rpc/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPool     common.Address = "0x88e6a0c2ddd26feeb64f039a2c41296fcb3f5640"
+	testAttacker common.Address = "0xae2fc483527b8ef99eb5d9b44875f005ba1fae13"
+	testVictim   common.Address = "0x1111111111111111111111111111111111111111"
+	testToken0   common.Address = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	testToken1   common.Address = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+)
+
+// encodeWords ABI encodes ints as 32 byte two's complement words
+func encodeWords(values ...int64) string {
+	data := HexPrefix
+	for _, v := range values {
+		n := big.NewInt(v)
+		if v < 0 {
+			n.Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		data += fmt.Sprintf("%064x", n)
+	}
+	return data
+}
+
+// v3Receipt creates the receipt of a tx doing a single Uniswap V3 swap
+func v3Receipt(position uint64, from, pool common.Address, amount0, amount1 int64) *Receipt {
+	return &Receipt{
+		TransactionHash:  common.Hash(fmt.Sprintf("0x%02x", position)),
+		TransactionIndex: fmt.Sprintf("0x%x", position),
+		From:             from,
+		Status:           "0x1",
+		Logs: []Log{{
+			Address:  pool,
+			Topics:   []string{UniswapV3SwapTopic, "0x00", "0x00"},
+			Data:     encodeWords(amount0, amount1, 0, 0, 0),
+			LogIndex: "0x0",
+		}},
+	}
+}
+
+// TestDecodeSwaps() tests decoding V2 and V3 swaps from receipts
+func TestDecodeSwaps(t *testing.T) {
+	v2 := &Receipt{
+		TransactionHash:  "0x02",
+		TransactionIndex: "0x2",
+		From:             testVictim,
+		Status:           "0x1",
+		Logs: []Log{
+			// a Transfer event is ignored
+			{Address: testToken0, Topics: []string{"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"}, Data: encodeWords(1)},
+			// amount0In, amount1In, amount0Out, amount1Out
+			{Address: testPool, Topics: []string{UniswapV2SwapTopic}, Data: encodeWords(0, 500, 100, 0), LogIndex: "0x5"},
+		},
+	}
+	reverted := v3Receipt(3, testVictim, testPool, 1, -1)
+	reverted.Status = "0x0"
+
+	swaps := decodeSwaps([]*Receipt{v3Receipt(1, testAttacker, testPool, -100, 500), v2, reverted})
+	require.Equal(t, []*Swap{
+		{
+			TxHash: "0x01", Position: 1, LogIndex: 0, From: testAttacker, Pool: testPool,
+			Protocol: ProtocolUniswapV3, Amount0: big.NewInt(-100), Amount1: big.NewInt(500),
+		},
+		{
+			TxHash: "0x02", Position: 2, LogIndex: 5, From: testVictim, Pool: testPool,
+			Protocol: ProtocolUniswapV2, Amount0: big.NewInt(-100), Amount1: big.NewInt(500),
+		},
+	}, swaps)
+	require.False(t, swaps[0].ZeroForOne())
+}
+
+// TestDetectSandwiches() tests the sandwich pattern matching
+func TestDetectSandwiches(t *testing.T) {
+	const otherPool = "0x2222222222222222222222222222222222222222"
+	swaps := decodeSwaps([]*Receipt{
+		// front-run: sells 1000 token1
+		v3Receipt(1, testAttacker, testPool, -500, 1000),
+		// a swap on another pool is not part of the sandwich
+		v3Receipt(2, testVictim, otherPool, -10, 20),
+		// victims: sell token1 as well
+		v3Receipt(3, testVictim, testPool, -200, 450),
+		v3Receipt(4, testAttacker, otherPool, 5, -5),
+		v3Receipt(5, "0x3333333333333333333333333333333333333333", testPool, -100, 230),
+		// a swap in the other direction is not a victim
+		v3Receipt(6, "0x4444444444444444444444444444444444444444", testPool, 10, -20),
+		// back-run: buys 1100 token1 back
+		v3Receipt(7, testAttacker, testPool, 500, -1100),
+	})
+	sandwiches := detectSandwiches(21_000_042, swaps)
+	require.Len(t, sandwiches, 1)
+	require.False(t, sandwiches[0].profitInToken0)
+	require.Equal(t, &database.Sandwich{
+		BlockNumber: 21_000_042,
+		Attacker:    testAttacker,
+		Pool:        testPool,
+		Protocol:    ProtocolUniswapV3,
+		FrontRunTx:  "0x01",
+		BackRunTx:   "0x07",
+		VictimTxs:   []common.Hash{"0x03", "0x05"},
+		Victims:     []common.Address{testVictim, "0x3333333333333333333333333333333333333333"},
+		Profit:      big.NewInt(100),
+	}, sandwiches[0].Sandwich)
+
+	// without a victim, buying and selling again is no sandwich
+	swaps = decodeSwaps([]*Receipt{
+		v3Receipt(1, testAttacker, testPool, -500, 1000),
+		v3Receipt(2, testAttacker, testPool, 500, -1100),
+	})
+	require.Empty(t, detectSandwiches(21_000_042, swaps))
+}
+
+// TestAnalyzeBlock() tests that the profit token of a sandwich is resolved, and cached
+func TestAnalyzeBlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRPCClient := mocks.NewMockRPCClient(ctrl)
+	tracer := NewBlockTracer(mockRPCClient, mocks.NewMockMEVTraceStorage(ctrl), getTestMetrics(t), common.SetupLogger(&common.LoggingOpts{
+		Service: "test",
+		Version: common.Version,
+	}))
+
+	word := func(address common.Address) *rpcclient.RPCResponse {
+		return &rpcclient.RPCResponse{Result: "0x000000000000000000000000" + string(address[2:])}
+	}
+	mockRPCClient.EXPECT().Call(gomock.Any(), CallRPC, map[string]string{"to": string(testPool), "data": Token0Selector}, "latest").
+		Return(word(testToken0), nil).Times(1)
+	mockRPCClient.EXPECT().Call(gomock.Any(), CallRPC, map[string]string{"to": string(testPool), "data": Token1Selector}, "latest").
+		Return(word(testToken1), nil).Times(1)
+
+	receipts := []*Receipt{
+		v3Receipt(1, testAttacker, testPool, 1000, -500),
+		v3Receipt(2, testVictim, testPool, 450, -200),
+		v3Receipt(3, testAttacker, testPool, -1100, 500),
+	}
+	for range 2 {
+		mevBlock := &database.MEVBlock{BlockNumber: 21_000_042}
+		tracer.analyzeBlock(t.Context(), receipts, mevBlock, nil)
+		require.Len(t, mevBlock.Sandwiches, 1)
+		require.Equal(t, testToken0, mevBlock.Sandwiches[0].ProfitToken)
+		require.Equal(t, big.NewInt(100), mevBlock.Sandwiches[0].Profit)
+	}
+}
@@ -0,0 +1,145 @@
+package blocktrace
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/holisticode/mev-rpc/common"
+)
+
+// topics of the Swap events of the supported DEXes
+const (
+	// Swap(address indexed sender, uint amount0In, uint amount1In, uint amount0Out, uint amount1Out, address indexed to)
+	UniswapV2SwapTopic = "0xd78ad95fa46c994b6551d0da85fc275fe613ce37657fb8d5e3d130840159d822"
+	// Swap(address indexed sender, address indexed recipient, int256 amount0, int256 amount1, uint160 sqrtPriceX96, uint128 liquidity, int24 tick)
+	UniswapV3SwapTopic = "0xc42079f94a6350d7e6235f29174924f928cc2ac818eb64fed8004e115fbcca67"
+)
+
+// names of the supported DEX protocols
+const (
+	ProtocolUniswapV2 = "uniswap_v2"
+	ProtocolUniswapV3 = "uniswap_v3"
+)
+
+// Swap is a trade on a DEX pool, decoded from its Swap event.
+// Amounts are seen from the pool: positive amounts were paid into the pool, negative ones out of it.
+type Swap struct {
+	TxHash   common.Hash
+	Position uint64
+	LogIndex uint64
+	// From is the sender of the transaction, not of the event
+	From     common.Address
+	Pool     common.Address
+	Protocol string
+	Amount0  *big.Int
+	Amount1  *big.Int
+}
+
+// ZeroForOne is true if token0 was sold to the pool for token1
+func (s *Swap) ZeroForOne() bool {
+	return s.Amount0.Sign() > 0
+}
+
+// decodeSwaps extracts the swaps of successful transactions, in block order
+func decodeSwaps(receipts []*Receipt) []*Swap {
+	swaps := []*Swap{}
+	for _, r := range receipts {
+		if !receiptSucceeded(r) {
+			continue
+		}
+		position := parseHexUint(r.TransactionIndex)
+		for i := range r.Logs {
+			l := &r.Logs[i]
+			if len(l.Topics) == 0 {
+				continue
+			}
+			var (
+				amount0, amount1 *big.Int
+				protocol         string
+			)
+			words := dataWords(l.Data)
+			switch strings.ToLower(l.Topics[0]) {
+			case UniswapV2SwapTopic:
+				if len(words) < 4 {
+					continue
+				}
+				// amountXIn - amountXOut
+				protocol = ProtocolUniswapV2
+				amount0 = new(big.Int).Sub(new(big.Int).SetBytes(words[0]), new(big.Int).SetBytes(words[2]))
+				amount1 = new(big.Int).Sub(new(big.Int).SetBytes(words[1]), new(big.Int).SetBytes(words[3]))
+			case UniswapV3SwapTopic:
+				if len(words) < 2 {
+					continue
+				}
+				protocol = ProtocolUniswapV3
+				amount0, amount1 = signedWord(words[0]), signedWord(words[1])
+			default:
+				continue
+			}
+			swaps = append(swaps, &Swap{
+				TxHash:   r.TransactionHash,
+				Position: position,
+				LogIndex: parseHexUint(l.LogIndex),
+				From:     r.From,
+				Pool:     l.Address,
+				Protocol: protocol,
+				Amount0:  amount0,
+				Amount1:  amount1,
+			})
+		}
+	}
+	return swaps
+}
+
+// receiptSucceeded is false for reverted transactions; pre-byzantium receipts have no status
+func receiptSucceeded(r *Receipt) bool {
+	return r.Status == "" || parseHexUint(r.Status) == 1
+}
+
+// parseHexUint parses a hex quantity, returning 0 if it is malformed
+func parseHexUint(s string) uint64 {
+	n, _ := strconv.ParseUint(sanitizeHexString(s), 16, 64)
+	return n
+}
+
+// dataWords splits ABI encoded data into 32 byte words
+func dataWords(data string) [][]byte {
+	raw := sanitizeHexString(data)
+	words := make([][]byte, 0, len(raw)/64)
+	for i := 0; i+64 <= len(raw); i += 64 {
+		word, ok := new(big.Int).SetString(raw[i:i+64], 16)
+		if !ok {
+			return nil
+		}
+		words = append(words, word.FillBytes(make([]byte, 32)))
+	}
+	return words
+}
+
+// signedWord decodes a two's complement int256
+func signedWord(word []byte) *big.Int {
+	n := new(big.Int).SetBytes(word)
+	if len(word) > 0 && word[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	return n
+}
+
+// wordAddress decodes an address from a 32 byte word or topic
+func wordAddress(word string) common.Address {
+	raw := sanitizeHexString(word)
+	if len(raw) < 40 {
+		return ""
+	}
+	return common.NormalizeAddress(HexPrefix + raw[len(raw)-40:])
+}
+
+// dataAddress decodes an address from a 32 byte data word
+func dataAddress(word []byte) common.Address {
+	if len(word) < 20 {
+		return ""
+	}
+	return common.Address(HexPrefix + hex.EncodeToString(word[len(word)-20:]))
+}
@@ -1,5 +1,7 @@
 package blocktrace
 
+import "github.com/holisticode/mev-rpc/common"
+
 // This file contains objects used to marshal/unmarshal to/from JSON
 
 // TraceBlockResponse is the wrapper for the trace_block response
@@ -7,25 +9,25 @@ type TraceBlockResponse []BlockData
 
 // BlockData is the actual block info in trace_block
 type BlockData struct {
-	Action              Action   `json:"action"`
-	BlockHash           string   `json:"blockHash"`   //nolint:tagliatelle
-	BlockNumber         uint64   `json:"blockNumber"` //nolint:tagliatelle
-	Result              Result   `json:"result"`
-	Subtraces           uint64   `json:"subtraces"`
-	TraceAddress        []uint64 `json:"traceAddress"`        //nolint:tagliatelle
-	TransactionHash     string   `json:"transactionHash"`     //nolint:tagliatelle
-	TransactionPosition uint64   `json:"transactionPosition"` //nolint:tagliatelle
-	Type                string   `json:"type"`
+	Action              Action      `json:"action"`
+	BlockHash           common.Hash `json:"blockHash"`   //nolint:tagliatelle
+	BlockNumber         uint64      `json:"blockNumber"` //nolint:tagliatelle
+	Result              Result      `json:"result"`
+	Subtraces           uint64      `json:"subtraces"`
+	TraceAddress        []uint64    `json:"traceAddress"`        //nolint:tagliatelle
+	TransactionHash     common.Hash `json:"transactionHash"`     //nolint:tagliatelle
+	TransactionPosition uint64      `json:"transactionPosition"` //nolint:tagliatelle
+	Type                string      `json:"type"`
 }
 
 // Action contains tx data in blocks
 type Action struct {
-	From     string `json:"from"`
-	CallType string `json:"callType"` //nolint:tagliatelle
-	Gas      string `json:"gas"`
-	Input    string `json:"input"`
-	To       string `json:"to"`
-	Value    string `json:"value"`
+	From     common.Address `json:"from"`
+	CallType string         `json:"callType"` //nolint:tagliatelle
+	Gas      string         `json:"gas"`
+	Input    string         `json:"input"`
+	To       common.Address `json:"to"`
+	Value    string         `json:"value"`
 }
 
 // Result is from the upper level BlockData
@@ -36,25 +38,45 @@ type Result struct {
 
 // Block is the representation of the eth_getBlockByHash RPC response
 type Block struct {
-	Number           string   `json:"number"`
-	Hash             string   `json:"hash"`
-	Transactions     []string `json:"transactions"`
-	TotalDifficulty  string   `json:"totalDifficulty"` //nolint:tagliatelle
-	LogsBloom        string   `json:"logsBloom"`       //nolint:tagliatelle
-	ReceiptsRoot     string   `json:"receiptsRoot"`    //nolint:tagliatelle
-	ExtraData        string   `json:"extraData"`       //nolint:tagliatelle
-	BaseFeePerGas    string   `json:"baseFeePerGas"`   //nolint:tagliatelle
-	Nonce            string   `json:"nonce"`
-	Miner            string   `json:"miner"`
-	Difficulty       string   `json:"difficulty"`
-	GasLimit         string   `json:"gasLimit"` //nolint:tagliatelle
-	GasUsed          string   `json:"gasUsed"`  //nolint:tagliatelle
-	Uncles           []string `json:"uncles"`
-	Sha3Uncles       string   `json:"sha3Uncles"` //nolint:tagliatelle
-	Size             string   `json:"size"`
-	TransactionsRoot string   `json:"transactionsRoot"` //nolint:tagliatelle
-	StateRoot        string   `json:"stateRoot"`        //nolint:tagliatelle
-	MixHash          string   `json:"mixHash"`          //nolint:tagliatelle
-	ParentHash       string   `json:"parentHash"`       //nolint:tagliatelle
-	Timestamp        string   `json:"timestamp"`
+	Number           string         `json:"number"`
+	Hash             string         `json:"hash"`
+	Transactions     []string       `json:"transactions"`
+	TotalDifficulty  string         `json:"totalDifficulty"` //nolint:tagliatelle
+	LogsBloom        string         `json:"logsBloom"`       //nolint:tagliatelle
+	ReceiptsRoot     string         `json:"receiptsRoot"`    //nolint:tagliatelle
+	ExtraData        string         `json:"extraData"`       //nolint:tagliatelle
+	BaseFeePerGas    string         `json:"baseFeePerGas"`   //nolint:tagliatelle
+	Nonce            string         `json:"nonce"`
+	Miner            common.Address `json:"miner"`
+	Difficulty       string         `json:"difficulty"`
+	GasLimit         string         `json:"gasLimit"` //nolint:tagliatelle
+	GasUsed          string         `json:"gasUsed"`  //nolint:tagliatelle
+	Uncles           []string       `json:"uncles"`
+	Sha3Uncles       string         `json:"sha3Uncles"` //nolint:tagliatelle
+	Size             string         `json:"size"`
+	TransactionsRoot string         `json:"transactionsRoot"` //nolint:tagliatelle
+	StateRoot        string         `json:"stateRoot"`        //nolint:tagliatelle
+	MixHash          string         `json:"mixHash"`          //nolint:tagliatelle
+	ParentHash       string         `json:"parentHash"`       //nolint:tagliatelle
+	Timestamp        string         `json:"timestamp"`
+}
+
+// Receipt is the representation of a transaction receipt, as returned by eth_getBlockReceipts
+type Receipt struct {
+	TransactionHash   common.Hash    `json:"transactionHash"`  //nolint:tagliatelle
+	TransactionIndex  string         `json:"transactionIndex"` //nolint:tagliatelle
+	From              common.Address `json:"from"`
+	To                common.Address `json:"to"`
+	Status            string         `json:"status"`
+	GasUsed           string         `json:"gasUsed"`           //nolint:tagliatelle
+	EffectiveGasPrice string         `json:"effectiveGasPrice"` //nolint:tagliatelle
+	Logs              []Log          `json:"logs"`
+}
+
+// Log is an event emitted by a transaction
+type Log struct {
+	Address  common.Address `json:"address"`
+	Topics   []string       `json:"topics"`
+	Data     string         `json:"data"`
+	LogIndex string         `json:"logIndex"` //nolint:tagliatelle
 }
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/flashbots/go-utils/rpcclient"
+	"github.com/holisticode/mev-rpc/blocktrace"
+	"github.com/holisticode/mev-rpc/database"
+	cli "github.com/urfave/cli/v2" // imports as package "cli"
+)
+
+var (
+	errInvalidRange = errors.New("--from must not be greater than --to")
+	errInvalidLease = errors.New("--lease-timeout must be at least 1s")
+	errNoPrices     = errors.New("--value-stored requires --prices-file")
+)
+
+var backfillFlags = []cli.Flag{
+	&cli.Uint64Flag{
+		Name:     "from",
+		Usage:    "first block to trace",
+		Required: true,
+	},
+	&cli.Uint64Flag{
+		Name:     "to",
+		Usage:    "last block to trace (inclusive)",
+		Required: true,
+	},
+	&cli.Uint64Flag{
+		Name:  "shard-size",
+		Value: 0,
+		Usage: "split the range into shards of this many blocks, claimed through the database by any number of backfill processes (0 disables sharding)",
+	},
+	&cli.IntFlag{
+		Name:  "workers",
+		Value: 1,
+		Usage: "number of shards to backfill in parallel in this process (requires --shard-size)",
+	},
+	&cli.DurationFlag{
+		Name:  "lease-timeout",
+		Value: 5 * time.Minute,
+		Usage: "time after which the shard of a worker which stopped renewing its lease is handed out again",
+	},
+	&cli.StringFlag{
+		Name:  "worker-id",
+		Value: "",
+		Usage: "identifies this process in the shards table (defaults to <hostname>-<pid>)",
+	},
+	&cli.BoolFlag{
+		Name:  "value-stored",
+		Value: false,
+		Usage: "also value the stored blocks of the range which have no USD values yet, with --prices-file",
+	},
+}
+
+var backfillCommand = &cli.Command{
+	Name:  "backfill",
+	Usage: "Trace a range of blocks once, skipping blocks already stored, then exit",
+	Flags: withFlags(backfillFlags, chainFlags, dbFlags, logFlags),
+	Action: func(cCtx *cli.Context) error {
+		from, to := cCtx.Uint64("from"), cCtx.Uint64("to")
+		if from > to {
+			return errInvalidRange
+		}
+		cfg, err := loadConfig(cCtx)
+		if err != nil {
+			return err
+		}
+		if err := errors.Join(cfg.ValidateDB(), cfg.ValidateTracer()); err != nil {
+			return err
+		}
+		log := setupLogger(cfg)
+		priceProvider, err := loadPrices(cfg, log)
+		if err != nil {
+			return err
+		}
+		if cCtx.Bool("value-stored") && priceProvider == nil {
+			return errNoPrices
+		}
+
+		// blocks already stored are looked up on the primary,
+		// since a lagging replica would make us trace them again
+		cfg.DB.ReplicaConnectionString = ""
+		storage, err := openStorage(cfg, log)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := storage.Close(); err != nil {
+				log.Error("failed to close database", "err", err)
+			}
+		}()
+
+		ctx, cancel := signal.NotifyContext(cCtx.Context, os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		rpcClient := rpcclient.NewClient(cfg.Tracer.RPCEndpoint)
+		tracer := blocktrace.NewBlockTracer(rpcClient, storage, nil, log)
+		tracer.CallTimeout = cfg.Tracer.CallTimeout
+		tracer.Analyze = cfg.Tracer.Analyze
+		tracer.Prices = priceProvider
+		tracer.ValueStored = cCtx.Bool("value-stored")
+
+		var failed uint64
+		if shardSize := cCtx.Uint64("shard-size"); shardSize > 0 {
+			failed, err = backfillSharded(ctx, cCtx, tracer, storage, shardSize)
+		} else {
+			failed, err = tracer.Backfill(ctx, from, to)
+		}
+		if err != nil {
+			log.Error("backfill aborted", "err", err)
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d blocks could not be traced", failed) //nolint:goerr113
+		}
+		return nil
+	},
+}
+
+// backfillSharded creates the shards for the range, if not created by another process yet,
+// and backfills them with --workers workers until no shard is left
+func backfillSharded(
+	ctx context.Context,
+	cCtx *cli.Context,
+	tracer *blocktrace.Tracer,
+	queue database.BackfillQueue,
+	shardSize uint64,
+) (uint64, error) {
+	lease := cCtx.Duration("lease-timeout")
+	if lease < time.Second {
+		return 0, errInvalidLease
+	}
+	workerID := cCtx.String("worker-id")
+	if workerID == "" {
+		hostname, _ := os.Hostname()
+		workerID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	from, to := cCtx.Uint64("from"), cCtx.Uint64("to")
+	if _, err := queue.CreateBackfillShards(ctx, from, to, shardSize); err != nil {
+		return 0, fmt.Errorf("failed to create backfill shards: %w", err)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed uint64
+		errs   []error
+	)
+	for i := range max(cCtx.Int("workers"), 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := tracer.BackfillShards(ctx, queue, from, to, fmt.Sprintf("%s-%d", workerID, i), lease)
+			mu.Lock()
+			defer mu.Unlock()
+			failed += n
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return failed, errors.Join(errs...)
+}
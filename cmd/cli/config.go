@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/holisticode/mev-rpc/config"
+	"github.com/holisticode/mev-rpc/database/vars"
+	cli "github.com/urfave/cli/v2" // imports as package "cli"
+)
+
+// defaults provides the default values of the flags
+var defaults = config.Default()
+
+// configFlag is accepted by all commands
+var configFlag = &cli.StringFlag{
+	Name:    "config",
+	Value:   "",
+	Usage:   "YAML or TOML config file; env vars and flags override its settings",
+	EnvVars: []string{"MEV_RPC_CONFIG"},
+}
+
+// configFields maps the flags to the config setting they override
+func configFields(cfg *config.Config) map[string]any {
+	return map[string]any{
+		"log-json":                     &cfg.Log.JSON,
+		"log-debug":                    &cfg.Log.Debug,
+		"log-uid":                      &cfg.Log.UID,
+		"log-service":                  &cfg.Log.Service,
+		"db-connection-string":         &cfg.DB.ConnectionString,
+		"db-query-timeout":             &cfg.DB.QueryTimeout,
+		"db-table-prefix":              &cfg.DB.TablePrefix,
+		"db-table-suffix":              &cfg.DB.TableSuffix,
+		"db-dont-apply-schema":         &cfg.DB.DontApplySchema,
+		"db-replica-connection-string": &cfg.DB.ReplicaConnectionString,
+		"db-max-open-conns":            &cfg.DB.MaxOpenConns,
+		"db-max-idle-conns":            &cfg.DB.MaxIdleConns,
+		"db-conn-max-lifetime":         &cfg.DB.ConnMaxLifetime,
+		"db-conn-max-idle-time":        &cfg.DB.ConnMaxIdleTime,
+		"rpc-endpoint":                 &cfg.Tracer.RPCEndpoint,
+		"call-timeout":                 &cfg.Tracer.CallTimeout,
+		"polling-interval":             &cfg.Tracer.PollingInterval,
+		"leader-election":              &cfg.Tracer.LeaderElection,
+		"analyze":                      &cfg.Tracer.Analyze,
+		"prices-file":                  &cfg.Tracer.PricesFile,
+		"listen-addr":                  &cfg.Server.ListenAddr,
+		"rpc-path":                     &cfg.Server.RPCPath,
+		"pprof":                        &cfg.Server.Pprof,
+		"drain-duration":               &cfg.Server.DrainDuration,
+		"max-block-lag":                &cfg.Server.MaxBlockLag,
+		"admin-token":                  &cfg.Server.AdminToken,
+		"labels-file":                  &cfg.Server.LabelsFile,
+		"metrics-addr":                 &cfg.Metrics.Addr,
+		"tracing-exporter":             &cfg.Tracing.Exporter,
+		"tracing-otlp-endpoint":        &cfg.Tracing.OTLPEndpoint,
+		"tracing-otlp-insecure":        &cfg.Tracing.OTLPInsecure,
+		"tracing-file":                 &cfg.Tracing.File,
+		"tracing-sample-ratio":         &cfg.Tracing.SampleRatio,
+		"graceful-shutdown-duration":   &cfg.Server.GracefulShutdownDuration,
+	}
+}
+
+// loadConfig builds the effective config of a command.
+// Precedence, from highest to lowest: flags, env vars, config file, defaults.
+func loadConfig(cCtx *cli.Context) (*config.Config, error) {
+	cfg := config.Default()
+	if path := cCtx.String(configFlag.Name); path != "" {
+		var err error
+		if cfg, err = config.Load(path); err != nil {
+			return nil, err
+		}
+	}
+
+	// IsSet is also true if the flag was set by its env var
+	for name, field := range configFields(cfg) {
+		if !cCtx.IsSet(name) {
+			continue
+		}
+		switch f := field.(type) {
+		case *bool:
+			*f = cCtx.Bool(name)
+		case *string:
+			*f = cCtx.String(name)
+		case *int:
+			*f = cCtx.Int(name)
+		case *uint64:
+			*f = cCtx.Uint64(name)
+		case *float64:
+			*f = cCtx.Float64(name)
+		case *time.Duration:
+			*f = cCtx.Duration(name)
+		default:
+			panic(fmt.Sprintf("flag %s: unsupported config field type %T", name, field))
+		}
+	}
+	// kept for backwards compatibility, --drain-duration takes precedence
+	if cCtx.IsSet("drain-seconds") && !cCtx.IsSet("drain-duration") {
+		cfg.Server.DrainDuration = time.Duration(cCtx.Int64("drain-seconds")) * time.Second
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	vars.SetTableNames(cfg.DB.TablePrefix, cfg.DB.TableSuffix)
+	return cfg, nil
+}
+
+var configCommand = &cli.Command{
+	Name:  "config",
+	Usage: "Inspect the configuration",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "print",
+			Usage: "Print the effective config, after applying the config file, env vars and flags, with secrets masked",
+			Flags: withFlags([]cli.Flag{&cli.StringFlag{
+				Name:  "format",
+				Value: "yaml",
+				Usage: "output format: yaml or toml",
+			}}, serveFlags, indexFlags, chainFlags, dbFlags, telemetryFlags, logFlags),
+			Action: func(cCtx *cli.Context) error {
+				cfg, err := loadConfig(cCtx)
+				if err != nil {
+					return err
+				}
+				// the output is meant to be shared, so it must not contain secrets
+				out, err := cfg.Redacted().Marshal(cCtx.String("format"))
+				if err != nil {
+					return err
+				}
+				_, err = cCtx.App.Writer.Write(out)
+				return err
+			},
+		},
+	},
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/holisticode/mev-rpc/export"
+	cli "github.com/urfave/cli/v2" // imports as package "cli"
+)
+
+var errInvalidWindow = errors.New("the start of the block or time range must not be after its end")
+
+var exportFlags = []cli.Flag{
+	&cli.Uint64Flag{
+		Name:  "from-block",
+		Usage: "first block to export (0 means unbounded)",
+	},
+	&cli.Uint64Flag{
+		Name:  "to-block",
+		Usage: "last block to export, inclusive (0 means unbounded)",
+	},
+	&cli.Uint64Flag{
+		Name:  "from-time",
+		Usage: "unix timestamp of the first block to export (0 means unbounded)",
+	},
+	&cli.Uint64Flag{
+		Name:  "to-time",
+		Usage: "unix timestamp of the last block to export, inclusive (0 means unbounded)",
+	},
+	&cli.StringSliceFlag{
+		Name:  "tables",
+		Value: cli.NewStringSlice(string(database.ExportBlocks), string(database.ExportTxs)),
+		Usage: "tables to export: blocks and/or txs",
+	},
+	&cli.StringFlag{
+		Name:  "format",
+		Value: string(export.FormatCSV),
+		Usage: "file format: csv, ndjson or parquet",
+	},
+	&cli.StringSliceFlag{
+		Name:  "block-columns",
+		Usage: "columns of the blocks to export, in this order (defaults to all)",
+	},
+	&cli.StringSliceFlag{
+		Name:  "tx-columns",
+		Usage: "columns of the txs to export, in this order (defaults to all)",
+	},
+	&cli.StringFlag{
+		Name:  "out-dir",
+		Value: "export",
+		Usage: "directory to write the files to; the files of an earlier export of the same tables and format are replaced",
+	},
+	&cli.Uint64Flag{
+		Name:  "chunk-rows",
+		Value: export.DefaultChunkRows,
+		Usage: "maximum number of rows per file (0 means unbounded)",
+	},
+	&cli.Uint64Flag{
+		Name:  "chunk-bytes",
+		Value: export.DefaultChunkBytes,
+		Usage: "approximate maximum size of a file in bytes (0 means unbounded)",
+	},
+}
+
+var exportCommand = &cli.Command{
+	Name:  "export",
+	Usage: "Export the stored blocks and transfers of a block or time range to CSV, NDJSON or Parquet files",
+	Flags: withFlags(exportFlags, dbFlags, logFlags),
+	Action: func(cCtx *cli.Context) error {
+		window := &database.StatsWindow{
+			FromBlock: cCtx.Uint64("from-block"),
+			ToBlock:   cCtx.Uint64("to-block"),
+			FromTime:  cCtx.Uint64("from-time"),
+			ToTime:    cCtx.Uint64("to-time"),
+		}
+		if (window.ToBlock > 0 && window.FromBlock > window.ToBlock) ||
+			(window.ToTime > 0 && window.FromTime > window.ToTime) {
+			return errInvalidWindow
+		}
+		format, err := export.ParseFormat(cCtx.String("format"))
+		if err != nil {
+			return err
+		}
+		// the columns are checked before connecting, so that typos fail fast
+		columns := map[database.ExportTable][]database.ExportColumn{}
+		tables := []database.ExportTable{}
+		for _, name := range cCtx.StringSlice("tables") {
+			table := database.ExportTable(name)
+			names := cCtx.StringSlice("block-columns")
+			if table == database.ExportTxs {
+				names = cCtx.StringSlice("tx-columns")
+			}
+			if columns[table], err = database.ExportColumns(table, names); err != nil {
+				return err
+			}
+			tables = append(tables, table)
+		}
+
+		cfg, err := loadDBConfig(cCtx)
+		if err != nil {
+			return err
+		}
+		log := setupLogger(cfg)
+		// exporting only reads, so it doesn't change the schema
+		cfg.DB.DontApplySchema = true
+		storage, err := openStorage(cfg, log)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := storage.Close(); err != nil {
+				log.Error("failed to close database", "err", err)
+			}
+		}()
+
+		ctx, cancel := signal.NotifyContext(cCtx.Context, os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		opts := &export.Opts{
+			Dir:        cCtx.String("out-dir"),
+			Format:     format,
+			ChunkRows:  cCtx.Uint64("chunk-rows"),
+			ChunkBytes: cCtx.Uint64("chunk-bytes"),
+		}
+		for _, table := range tables {
+			log.Info("Exporting", "table", table, "format", format, "dir", opts.Dir)
+			res, err := export.Table(ctx, storage, table, columns[table], window, opts)
+			if err != nil {
+				log.Error("export failed", "table", table, "err", err)
+				return err
+			}
+			for _, f := range res.Files {
+				fmt.Fprintln(cCtx.App.Writer, f)
+			}
+			log.Info("Exported", "table", table, "rows", res.Rows, "files", len(res.Files))
+		}
+		return nil
+	},
+}
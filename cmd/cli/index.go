@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/flashbots/go-utils/rpcclient"
+	"github.com/holisticode/mev-rpc/blocktrace"
+	"github.com/holisticode/mev-rpc/database"
+	cli "github.com/urfave/cli/v2" // imports as package "cli"
+)
+
+var indexCommand = &cli.Command{
+	Name:  "index",
+	Usage: "Follow the chain head and index MEV blocks into the database (does not serve the API)",
+	Flags: withFlags(indexFlags, chainFlags, dbFlags, telemetryFlags, logFlags),
+	Action: func(cCtx *cli.Context) error {
+		svc, err := setupService(cCtx, true)
+		if err != nil {
+			return err
+		}
+		defer svc.close()
+
+		// without the RPC server, the metrics server must be run here
+		if metricsAddr := svc.cfg.Metrics.Addr; metricsAddr != "" {
+			go func() {
+				svc.log.With("metricsAddress", metricsAddr).Info("Starting metrics server")
+				if err := svc.metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					svc.log.Error("metrics server failed", "err", err)
+				}
+			}()
+			defer func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := svc.metricsSrv.Shutdown(ctx); err != nil {
+					svc.log.Error("Graceful metrics server shutdown failed", "err", err)
+				}
+			}()
+		}
+
+		tracer := startTracer(svc)
+		waitForSignal()
+
+		// the tracer is stopped before the DB is closed
+		svc.log.Info("Shutting down the application")
+		stopTracer(svc, tracer)
+		return nil
+	},
+}
+
+func startTracer(svc *service) *blocktrace.Tracer {
+	svc.log.Info("Starting tracer...")
+	rpcClient := rpcclient.NewClient(svc.cfg.Tracer.RPCEndpoint)
+	tracer := blocktrace.NewBlockTracer(rpcClient, svc.storage, svc.metricsSrv, svc.log)
+	tracer.CallTimeout = svc.cfg.Tracer.CallTimeout
+	tracer.Analyze = svc.cfg.Tracer.Analyze
+	tracer.Prices = svc.prices
+	if svc.cfg.Tracer.LeaderElection {
+		tracer.SetElector(database.NewLeaderElector(svc.storage.DB, svc.log))
+	}
+	go tracer.Start(context.Background(), svc.cfg.Tracer.PollingInterval)
+	return tracer
+}
+
+func stopTracer(svc *service, tracer *blocktrace.Tracer) {
+	tracer.Stop()
+	tracer.Wait()
+	svc.log.Info("Tracer stopped")
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/config"
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/holisticode/mev-rpc/metrics"
+	"github.com/holisticode/mev-rpc/prices"
+	"github.com/holisticode/mev-rpc/tracing"
+	cli "github.com/urfave/cli/v2" // imports as package "cli"
+)
+
+// logFlags are accepted by all commands
+var logFlags = []cli.Flag{
+	&cli.BoolFlag{
+		Name:    "log-json",
+		Value:   defaults.Log.JSON,
+		Usage:   "log in JSON format",
+		EnvVars: []string{"MEV_RPC_LOG_JSON"},
+	},
+	&cli.BoolFlag{
+		Name:    "log-debug",
+		Value:   defaults.Log.Debug,
+		Usage:   "log debug messages",
+		EnvVars: []string{"MEV_RPC_LOG_DEBUG"},
+	},
+	&cli.BoolFlag{
+		Name:    "log-uid",
+		Value:   defaults.Log.UID,
+		Usage:   "generate a uuid and add to all log messages",
+		EnvVars: []string{"MEV_RPC_LOG_UID"},
+	},
+	&cli.StringFlag{
+		Name:    "log-service",
+		Value:   defaults.Log.Service,
+		Usage:   "add 'service' tag to logs",
+		EnvVars: []string{"MEV_RPC_LOG_SERVICE"},
+	},
+}
+
+// dbFlags are accepted by all commands using the database
+var dbFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "db-connection-string",
+		Value:   defaults.DB.ConnectionString,
+		Usage:   "postgres database backend",
+		EnvVars: []string{"MEV_RPC_DB_CONNECTION_STRING"},
+	},
+	&cli.DurationFlag{
+		Name:    "db-query-timeout",
+		Value:   defaults.DB.QueryTimeout,
+		Usage:   "maximum duration of a single database query",
+		EnvVars: []string{"MEV_RPC_DB_QUERY_TIMEOUT"},
+	},
+	&cli.StringFlag{
+		Name:    "db-table-prefix",
+		Value:   defaults.DB.TablePrefix,
+		Usage:   "prefix of the table names",
+		EnvVars: []string{"MEV_RPC_DB_TABLE_PREFIX", "DB_TABLE_PREFIX"},
+	},
+	&cli.StringFlag{
+		Name:    "db-table-suffix",
+		Value:   defaults.DB.TableSuffix,
+		Usage:   "suffix of the table names",
+		EnvVars: []string{"MEV_RPC_DB_TABLE_SUFFIX", "DB_TABLE_SUFFIX"},
+	},
+	&cli.BoolFlag{
+		Name:    "db-dont-apply-schema",
+		Value:   defaults.DB.DontApplySchema,
+		Usage:   "don't apply pending migrations on start",
+		EnvVars: []string{"MEV_RPC_DB_DONT_APPLY_SCHEMA", "DB_DONT_APPLY_SCHEMA"},
+	},
+	&cli.StringFlag{
+		Name:    "db-replica-connection-string",
+		Value:   defaults.DB.ReplicaConnectionString,
+		Usage:   "optional postgres read replica, serving the JSON-RPC queries",
+		EnvVars: []string{"MEV_RPC_DB_REPLICA_CONNECTION_STRING"},
+	},
+	&cli.IntFlag{
+		Name:    "db-max-open-conns",
+		Value:   defaults.DB.MaxOpenConns,
+		Usage:   "maximum number of open connections per database pool (0 means no limit)",
+		EnvVars: []string{"MEV_RPC_DB_MAX_OPEN_CONNS"},
+	},
+	&cli.IntFlag{
+		Name:    "db-max-idle-conns",
+		Value:   defaults.DB.MaxIdleConns,
+		Usage:   "maximum number of idle connections per database pool",
+		EnvVars: []string{"MEV_RPC_DB_MAX_IDLE_CONNS"},
+	},
+	&cli.DurationFlag{
+		Name:    "db-conn-max-lifetime",
+		Value:   defaults.DB.ConnMaxLifetime,
+		Usage:   "maximum time a database connection is reused (0 means forever)",
+		EnvVars: []string{"MEV_RPC_DB_CONN_MAX_LIFETIME"},
+	},
+	&cli.DurationFlag{
+		Name:    "db-conn-max-idle-time",
+		Value:   defaults.DB.ConnMaxIdleTime,
+		Usage:   "maximum time a database connection may be idle (0 means forever)",
+		EnvVars: []string{"MEV_RPC_DB_CONN_MAX_IDLE_TIME"},
+	},
+}
+
+// telemetryFlags are accepted by the long running commands
+var telemetryFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "metrics-addr",
+		Value:   defaults.Metrics.Addr,
+		Usage:   "address to listen on for Prometheus metrics",
+		EnvVars: []string{"MEV_RPC_METRICS_ADDR"},
+	},
+	&cli.StringFlag{
+		Name:    "tracing-exporter",
+		Value:   defaults.Tracing.Exporter,
+		Usage:   "OpenTelemetry trace exporter: none, otlp, stdout or file",
+		EnvVars: []string{"MEV_RPC_TRACING_EXPORTER"},
+	},
+	&cli.StringFlag{
+		Name:    "tracing-otlp-endpoint",
+		Value:   defaults.Tracing.OTLPEndpoint,
+		Usage:   "host:port of the OTLP HTTP collector (defaults to the OTEL_EXPORTER_OTLP_* env vars)",
+		EnvVars: []string{"MEV_RPC_TRACING_OTLP_ENDPOINT"},
+	},
+	&cli.BoolFlag{
+		Name:    "tracing-otlp-insecure",
+		Value:   defaults.Tracing.OTLPInsecure,
+		Usage:   "disable TLS towards the OTLP collector",
+		EnvVars: []string{"MEV_RPC_TRACING_OTLP_INSECURE"},
+	},
+	&cli.StringFlag{
+		Name:    "tracing-file",
+		Value:   defaults.Tracing.File,
+		Usage:   "file to write spans to with the file exporter",
+		EnvVars: []string{"MEV_RPC_TRACING_FILE"},
+	},
+	&cli.Float64Flag{
+		Name:    "tracing-sample-ratio",
+		Value:   defaults.Tracing.SampleRatio,
+		Usage:   "fraction of traces to sample",
+		EnvVars: []string{"MEV_RPC_TRACING_SAMPLE_RATIO"},
+	},
+}
+
+// chainFlags are accepted by all commands querying the chain
+var chainFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "rpc-endpoint",
+		Value:   defaults.Tracer.RPCEndpoint,
+		Usage:   "chain rpc endpoint",
+		EnvVars: []string{"MEV_RPC_RPC_ENDPOINT"},
+	},
+	&cli.DurationFlag{
+		Name:    "call-timeout",
+		Value:   defaults.Tracer.CallTimeout,
+		Usage:   "maximum duration of a single call to the chain rpc endpoint",
+		EnvVars: []string{"MEV_RPC_CALL_TIMEOUT"},
+	},
+	&cli.BoolFlag{
+		Name:    "analyze",
+		Value:   defaults.Tracer.Analyze,
+		Usage:   "detect MEV such as sandwiches from the receipts of each block (requires eth_getBlockReceipts)",
+		EnvVars: []string{"MEV_RPC_ANALYZE"},
+	},
+	&cli.StringFlag{
+		Name:    "prices-file",
+		Value:   defaults.Tracer.PricesFile,
+		Usage:   "CSV file of historical ETH/USD prices (time,price) to value MEV in USD",
+		EnvVars: []string{"MEV_RPC_PRICES_FILE"},
+	},
+}
+
+// indexFlags are accepted by the commands following the chain head
+var indexFlags = []cli.Flag{
+	&cli.BoolFlag{
+		Name:    "leader-election",
+		Value:   defaults.Tracer.LeaderElection,
+		Usage:   "only index while holding a Postgres advisory lock, so that multiple replicas can run against the same database",
+		EnvVars: []string{"MEV_RPC_LEADER_ELECTION"},
+	},
+	&cli.DurationFlag{
+		Name:    "polling-interval",
+		Value:   defaults.Tracer.PollingInterval,
+		Usage:   "how often to poll the chain for new blocks",
+		EnvVars: []string{"MEV_RPC_POLLING_INTERVAL"},
+	},
+}
+
+func main() {
+	app := &cli.App{
+		Name:  "mev-rpc",
+		Usage: "Index MEV coinbase transfers and serve them via JSON-RPC",
+		Commands: []*cli.Command{
+			serveCommand,
+			indexCommand,
+			runCommand,
+			backfillCommand,
+			exportCommand,
+			migrateCommand,
+			configCommand,
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// withFlags concatenates flag groups, and adds the --config flag
+func withFlags(groups ...[]cli.Flag) []cli.Flag {
+	flags := []cli.Flag{configFlag}
+	for _, g := range groups {
+		flags = append(flags, g...)
+	}
+	return flags
+}
+
+func setupLogger(cfg *config.Config) *slog.Logger {
+	log := common.SetupLogger(&common.LoggingOpts{
+		Debug:   cfg.Log.Debug,
+		JSON:    cfg.Log.JSON,
+		Service: cfg.Log.Service,
+		Version: common.Version,
+	})
+	if cfg.Log.UID {
+		id := uuid.Must(uuid.NewRandom())
+		log = log.With("uid", id.String())
+	}
+	return log
+}
+
+// setupTracing configures tracing; the returned function flushes pending spans
+func setupTracing(ctx context.Context, cfg *config.Config, log *slog.Logger) (func(), error) {
+	shutdown, err := tracing.Setup(ctx, &tracing.Opts{
+		Exporter:     cfg.Tracing.Exporter,
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+		OTLPInsecure: cfg.Tracing.OTLPInsecure,
+		FilePath:     cfg.Tracing.File,
+		SampleRatio:  cfg.Tracing.SampleRatio,
+		Service:      cfg.Log.Service,
+		Version:      common.Version,
+	})
+	if err != nil {
+		log.Error("failed to set up tracing", "err", err)
+		return nil, err
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			log.Error("failed to flush traces", "err", err)
+		}
+	}, nil
+}
+
+func openStorage(cfg *config.Config, log *slog.Logger) (*database.DatabaseService, error) {
+	log.Debug("Creating DB backend connection...")
+	storage, err := database.NewStorage(cfg.DB.ConnectionString, log, &database.Opts{
+		QueryTimeout:   cfg.DB.QueryTimeout,
+		SkipMigrations: cfg.DB.DontApplySchema,
+		Pool:           poolOpts(cfg),
+		ReplicaDSN:     cfg.DB.ReplicaConnectionString,
+	})
+	if err != nil {
+		log.Error("failed to create database service", "err", err)
+		return nil, err
+	}
+	return storage, nil
+}
+
+func poolOpts(cfg *config.Config) *database.PoolOpts {
+	return &database.PoolOpts{
+		MaxOpenConns:    cfg.DB.MaxOpenConns,
+		MaxIdleConns:    cfg.DB.MaxIdleConns,
+		ConnMaxLifetime: cfg.DB.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.DB.ConnMaxIdleTime,
+	}
+}
+
+// service holds what the long running commands share
+type service struct {
+	cfg             *config.Config
+	log             *slog.Logger
+	storage         *database.DatabaseService
+	metricsSrv      *metrics.MetricsServer
+	shutdownTracing func()
+	// prices is only set for the commands tracing the chain, if a prices file is configured
+	prices prices.Provider
+}
+
+// setupService loads the config, and sets up logging, tracing,
+// the storage and the metrics server (not started).
+// With tracer, the settings needed to trace the chain are required, and the prices are loaded.
+func setupService(cCtx *cli.Context, tracer bool) (*service, error) {
+	cfg, err := loadConfig(cCtx)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.ValidateDB(); err != nil {
+		return nil, err
+	}
+	if tracer {
+		if err := cfg.ValidateTracer(); err != nil {
+			return nil, err
+		}
+	}
+
+	log := setupLogger(cfg)
+	var priceProvider prices.Provider
+	if tracer {
+		if priceProvider, err = loadPrices(cfg, log); err != nil {
+			return nil, err
+		}
+	}
+	shutdownTracing, err := setupTracing(cCtx.Context, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := openStorage(cfg, log)
+	if err != nil {
+		shutdownTracing()
+		return nil, err
+	}
+	metricsSrv, err := metrics.New(common.PackageName, cfg.Metrics.Addr)
+	if err != nil {
+		log.Error("failed to create metrics server", "err", err)
+		shutdownTracing()
+		_ = storage.Close()
+		return nil, err
+	}
+	return &service{
+		cfg:             cfg,
+		log:             log,
+		storage:         storage,
+		metricsSrv:      metricsSrv,
+		shutdownTracing: shutdownTracing,
+		prices:          priceProvider,
+	}, nil
+}
+
+// loadPrices loads the configured prices file; it returns nil if there is none
+func loadPrices(cfg *config.Config, log *slog.Logger) (prices.Provider, error) {
+	path := cfg.Tracer.PricesFile
+	if path == "" {
+		return nil, nil
+	}
+	provider, err := prices.LoadCSV(path)
+	if err != nil {
+		log.Error("failed to read prices file", "path", path, "err", err)
+		return nil, err
+	}
+	log.Info("Prices loaded", "path", path)
+	return provider, nil
+}
+
+// close closes the DB and flushes traces; it must be called last
+func (s *service) close() {
+	if err := s.storage.Close(); err != nil {
+		s.log.Error("failed to close database", "err", err)
+	} else {
+		s.log.Info("Database closed")
+	}
+	s.shutdownTracing()
+}
+
+// waitForSignal blocks until SIGINT or SIGTERM is received
+func waitForSignal() {
+	exit := make(chan os.Signal, 1)
+	signal.Notify(exit, os.Interrupt, syscall.SIGTERM)
+	<-exit
+}
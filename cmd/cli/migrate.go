@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/holisticode/mev-rpc/config"
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/jmoiron/sqlx"
+	cli "github.com/urfave/cli/v2" // imports as package "cli"
+)
+
+var migrateStepsFlag = &cli.IntFlag{
+	Name:  "steps",
+	Value: 0,
+	Usage: "maximum number of migrations to apply or revert (0 means all)",
+}
+
+var migrateCommand = &cli.Command{
+	Name:  "migrate",
+	Usage: "Manage the database schema",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "up",
+			Usage: "Apply pending migrations",
+			Flags: withFlags([]cli.Flag{migrateStepsFlag}, dbFlags, logFlags),
+			Action: func(cCtx *cli.Context) error {
+				return runMigrate(cCtx, database.MigrateUp, "applied")
+			},
+		},
+		{
+			Name:  "down",
+			Usage: "Revert applied migrations, latest first (one by default)",
+			Flags: withFlags([]cli.Flag{&cli.IntFlag{
+				Name:  migrateStepsFlag.Name,
+				Value: 1,
+				Usage: migrateStepsFlag.Usage,
+			}}, dbFlags, logFlags),
+			Action: func(cCtx *cli.Context) error {
+				return runMigrate(cCtx, database.MigrateDown, "reverted")
+			},
+		},
+		{
+			Name:  "status",
+			Usage: "List migrations and whether they have been applied",
+			Flags: withFlags(dbFlags, logFlags),
+			Action: func(cCtx *cli.Context) error {
+				cfg, err := loadDBConfig(cCtx)
+				if err != nil {
+					return err
+				}
+				db, err := database.Connect(cfg.DB.ConnectionString, poolOpts(cfg))
+				if err != nil {
+					return err
+				}
+				defer db.Close()
+				status, err := database.GetMigrationStatus(db)
+				if err != nil {
+					return err
+				}
+				for _, st := range status {
+					applied := "pending"
+					if st.AppliedAt != nil {
+						applied = st.AppliedAt.Format(time.RFC3339)
+					}
+					fmt.Fprintf(cCtx.App.Writer, "%-30s %s\n", st.ID, applied)
+				}
+				return nil
+			},
+		},
+	},
+}
+
+// runMigrate applies or reverts migrations, depending on migrate
+func runMigrate(cCtx *cli.Context, migrate func(context.Context, *sqlx.DB, int) (int, error), verb string) error {
+	cfg, err := loadDBConfig(cCtx)
+	if err != nil {
+		return err
+	}
+	log := setupLogger(cfg)
+	db, err := database.Connect(cfg.DB.ConnectionString, poolOpts(cfg))
+	if err != nil {
+		log.Error("failed to connect to database", "err", err)
+		return err
+	}
+	defer db.Close()
+
+	n, err := migrate(cCtx.Context, db, cCtx.Int("steps"))
+	if err != nil {
+		log.Error("migration failed", "err", err)
+		return err
+	}
+	log.Info("migrations "+verb, "count", n)
+	return nil
+}
+
+// loadDBConfig loads the config, which must contain the DB settings
+func loadDBConfig(cCtx *cli.Context) (*config.Config, error) {
+	cfg, err := loadConfig(cCtx)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.ValidateDB(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	cli "github.com/urfave/cli/v2" // imports as package "cli"
+)
+
+var runCommand = &cli.Command{
+	Name:  "run",
+	Usage: "Index and serve the JSON-RPC API in a single process",
+	Flags: withFlags(serveFlags, indexFlags, chainFlags, dbFlags, telemetryFlags, logFlags),
+	Action: func(cCtx *cli.Context) error {
+		svc, err := setupService(cCtx, true)
+		if err != nil {
+			return err
+		}
+		defer svc.close()
+
+		tracer := startTracer(svc)
+		srv, err := newServer(svc, tracer)
+		if err != nil {
+			stopTracer(svc, tracer)
+			return err
+		}
+		srv.RunInBackground()
+		waitForSignal()
+
+		// Shutdown in order:
+		// the tracer is stopped first, so that it doesn't write anymore,
+		// then the RPC server finishes the requests in flight,
+		// and only then the DB connections are closed
+		svc.log.Info("Shutting down the application")
+		stopTracer(svc, tracer)
+		srv.Shutdown()
+		return nil
+	},
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/holisticode/mev-rpc/httpserver"
+	cli "github.com/urfave/cli/v2" // imports as package "cli"
+)
+
+var serveFlags = []cli.Flag{
+	&cli.Uint64Flag{
+		Name:    "max-block-lag",
+		Value:   defaults.Server.MaxBlockLag,
+		Usage:   "number of blocks the tracer, or with serve the stored data, can lag behind the chain head before the server reports not ready",
+		EnvVars: []string{"MEV_RPC_MAX_BLOCK_LAG"},
+	},
+	&cli.StringFlag{
+		Name:    "listen-addr",
+		Value:   defaults.Server.ListenAddr,
+		Usage:   "address to listen on for API",
+		EnvVars: []string{"MEV_RPC_LISTEN_ADDR"},
+	},
+	&cli.StringFlag{
+		Name:    "rpc-path",
+		Value:   defaults.Server.RPCPath,
+		Usage:   "path to serve the JSON-RPC API at, next to the health endpoints",
+		EnvVars: []string{"MEV_RPC_RPC_PATH"},
+	},
+	&cli.BoolFlag{
+		Name:    "pprof",
+		Value:   defaults.Server.Pprof,
+		Usage:   "enable pprof debug endpoint",
+		EnvVars: []string{"MEV_RPC_PPROF"},
+	},
+	&cli.DurationFlag{
+		Name:    "drain-duration",
+		Value:   defaults.Server.DrainDuration,
+		Usage:   "time to wait in drain HTTP request",
+		EnvVars: []string{"MEV_RPC_DRAIN_DURATION"},
+	},
+	&cli.Int64Flag{
+		Name:   "drain-seconds",
+		Usage:  "deprecated, use --drain-duration",
+		Hidden: true,
+	},
+	&cli.DurationFlag{
+		Name:    "graceful-shutdown-duration",
+		Value:   defaults.Server.GracefulShutdownDuration,
+		Usage:   "maximum time to finish the requests in flight on shutdown",
+		EnvVars: []string{"MEV_RPC_GRACEFUL_SHUTDOWN_DURATION"},
+	},
+	&cli.StringFlag{
+		Name:    "admin-token",
+		Value:   defaults.Server.AdminToken,
+		Usage:   "bearer token enabling the admin JSON-RPC methods at " + httpserver.AdminRPCPath,
+		EnvVars: []string{"MEV_RPC_ADMIN_TOKEN"},
+	},
+	&cli.StringFlag{
+		Name:    "labels-file",
+		Value:   defaults.Server.LabelsFile,
+		Usage:   "CSV or JSON file of address labels to import on start",
+		EnvVars: []string{"MEV_RPC_LABELS_FILE"},
+	},
+}
+
+var serveCommand = &cli.Command{
+	Name:  "serve",
+	Usage: "Serve the JSON-RPC API from the database (does not index)",
+	Flags: withFlags(serveFlags, dbFlags, telemetryFlags, logFlags),
+	Action: func(cCtx *cli.Context) error {
+		svc, err := setupService(cCtx, false)
+		if err != nil {
+			return err
+		}
+		defer svc.close()
+
+		srv, err := newServer(svc, nil)
+		if err != nil {
+			return err
+		}
+		srv.RunInBackground()
+		waitForSignal()
+
+		// the requests in flight are finished before the DB is closed
+		svc.log.Info("Shutting down the application")
+		srv.Shutdown()
+		return nil
+	},
+}
+
+// newServer creates the JSON-RPC server. If syncStatus is nil,
+// readiness depends on the age of the stored data instead of the sync state of a tracer.
+func newServer(svc *service, syncStatus httpserver.SyncStatusProvider) (*httpserver.Server, error) {
+	if err := importLabels(svc); err != nil {
+		return nil, err
+	}
+	svc.log.Info("Starting RPC server...")
+	cfg := &httpserver.HTTPServerConfig{
+		ListenAddr:  svc.cfg.Server.ListenAddr,
+		RPCPath:     svc.cfg.Server.RPCPath,
+		MetricsAddr: svc.cfg.Metrics.Addr,
+		MetricsSrv:  svc.metricsSrv,
+		Log:         svc.log,
+		EnablePprof: svc.cfg.Server.Pprof,
+		DBService:   svc.storage,
+		AdminToken:  svc.cfg.Server.AdminToken,
+
+		DrainDuration:            svc.cfg.Server.DrainDuration,
+		GracefulShutdownDuration: svc.cfg.Server.GracefulShutdownDuration,
+		ReadTimeout:              svc.cfg.Server.ReadTimeout,
+		WriteTimeout:             svc.cfg.Server.WriteTimeout,
+		MaxBlockLag:              svc.cfg.Server.MaxBlockLag,
+	}
+	if syncStatus != nil {
+		cfg.SyncStatus = syncStatus
+	}
+	srv, err := httpserver.New(cfg)
+	if err != nil {
+		svc.log.Error("failed to create server", "err", err)
+		return nil, err
+	}
+	return srv, nil
+}
+
+// importLabels creates or replaces the labels of the configured labels file, if any
+func importLabels(svc *service) error {
+	path := svc.cfg.Server.LabelsFile
+	if path == "" {
+		return nil
+	}
+	labels, err := database.LoadLabelsFile(path)
+	if err != nil {
+		svc.log.Error("failed to read labels file", "path", path, "err", err)
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := svc.storage.ImportLabels(ctx, labels); err != nil {
+		svc.log.Error("failed to import labels", "path", path, "err", err)
+		return err
+	}
+	svc.log.Info("Labels imported", "path", path, "labels", len(labels))
+	return nil
+}
@@ -0,0 +1,102 @@
+package common
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	ErrInvalidAddress = errors.New("address must be 0x-prefixed 20 bytes hex")
+	ErrInvalidHash    = errors.New("hash must be 0x-prefixed 32 bytes hex")
+)
+
+var (
+	addressRegexp = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	hashRegexp    = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+)
+
+// Address is a hex address, normalized to lower case.
+// It is lower cased when decoded from JSON, so that data from the node and from clients compare equal.
+type Address string
+
+// Hash is a hex tx or block hash, normalized to lower case like Address
+type Hash string
+
+// NormalizeAddress lower cases an address without validating it, e.g. for data from the node
+func NormalizeAddress(s string) Address {
+	return Address(strings.ToLower(strings.TrimSpace(s)))
+}
+
+// ParseAddress validates and normalizes an address, e.g. from a client
+func ParseAddress(s string) (Address, error) {
+	if !addressRegexp.MatchString(s) {
+		return "", ErrInvalidAddress
+	}
+	return NormalizeAddress(s), nil
+}
+
+// NormalizeHash lower cases a hash without validating it
+func NormalizeHash(s string) Hash {
+	return Hash(strings.ToLower(strings.TrimSpace(s)))
+}
+
+// ParseHash validates and normalizes a hash
+func ParseHash(s string) (Hash, error) {
+	if !hashRegexp.MatchString(s) {
+		return "", ErrInvalidHash
+	}
+	return NormalizeHash(s), nil
+}
+
+func (a Address) String() string {
+	return string(a)
+}
+
+func (h Hash) String() string {
+	return string(h)
+}
+
+// Checksum returns the EIP-55 mixed case encoding of a valid address,
+// and the address as it is otherwise
+func (a Address) Checksum() string {
+	if !addressRegexp.MatchString(string(a)) {
+		return string(a)
+	}
+	lower := strings.ToLower(string(a)[2:])
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(lower))
+	digest := hex.EncodeToString(h.Sum(nil))
+	out := []byte(lower)
+	for i, c := range out {
+		// a letter is upper cased if the matching nibble of the hash is >= 8
+		if c >= 'a' && digest[i] >= '8' {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
+
+// UnmarshalJSON normalizes the address; it is not validated, since the node returns empty addresses
+func (a *Address) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*a = NormalizeAddress(s)
+	return nil
+}
+
+// UnmarshalJSON normalizes the hash
+func (h *Hash) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*h = NormalizeHash(s)
+	return nil
+}
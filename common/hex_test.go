@@ -0,0 +1,50 @@
+package common
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksum(t *testing.T) {
+	// test vectors from EIP-55
+	for _, expected := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		a, err := ParseAddress(expected)
+		require.NoError(t, err)
+		require.Equal(t, Address(strings.ToLower(expected)), a)
+		require.Equal(t, expected, a.Checksum())
+	}
+	// invalid addresses are returned as they are
+	require.Equal(t, "0x1234", Address("0x1234").Checksum())
+}
+
+func TestParseHex(t *testing.T) {
+	_, err := ParseAddress("0x1234")
+	require.ErrorIs(t, err, ErrInvalidAddress)
+	_, err = ParseAddress(strings.Repeat("a", 40))
+	require.ErrorIs(t, err, ErrInvalidAddress)
+
+	hash := "0x" + strings.Repeat("aB", 32)
+	h, err := ParseHash(hash)
+	require.NoError(t, err)
+	require.Equal(t, Hash(strings.ToLower(hash)), h)
+	_, err = ParseHash(hash[:64])
+	require.ErrorIs(t, err, ErrInvalidHash)
+}
+
+func TestUnmarshalHex(t *testing.T) {
+	var v struct {
+		Address Address `json:"address"`
+		Hash    Hash    `json:"hash"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"address": "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "hash": "0xAB"}`), &v))
+	require.Equal(t, Address("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"), v.Address)
+	require.Equal(t, Hash("0xab"), v.Hash)
+}
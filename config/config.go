@@ -0,0 +1,263 @@
+// Package config contains the settings of all commands, which can be read from a YAML or TOML file
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/holisticode/mev-rpc/blocktrace"
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/holisticode/mev-rpc/database/vars"
+	"github.com/holisticode/mev-rpc/httpserver"
+	"github.com/holisticode/mev-rpc/tracing"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown config file format, use .yaml, .yml or .toml")
+	ErrInvalidConfig = errors.New("invalid config")
+)
+
+type Config struct {
+	Log     LogConfig     `yaml:"log" toml:"log"`
+	DB      DBConfig      `yaml:"db" toml:"db"`
+	Tracer  TracerConfig  `yaml:"tracer" toml:"tracer"`
+	Server  ServerConfig  `yaml:"server" toml:"server"`
+	Metrics MetricsConfig `yaml:"metrics" toml:"metrics"`
+	Tracing TracingConfig `yaml:"tracing" toml:"tracing"`
+}
+
+type LogConfig struct {
+	JSON    bool   `yaml:"json" toml:"json"`
+	Debug   bool   `yaml:"debug" toml:"debug"`
+	UID     bool   `yaml:"uid" toml:"uid"`
+	Service string `yaml:"service" toml:"service"`
+}
+
+type DBConfig struct {
+	ConnectionString string        `yaml:"connection_string" toml:"connection_string"`
+	QueryTimeout     time.Duration `yaml:"query_timeout" toml:"query_timeout"`
+	TablePrefix      string        `yaml:"table_prefix" toml:"table_prefix"`
+	TableSuffix      string        `yaml:"table_suffix" toml:"table_suffix"`
+	// DontApplySchema doesn't apply pending migrations on start
+	DontApplySchema bool `yaml:"dont_apply_schema" toml:"dont_apply_schema"`
+	// ReplicaConnectionString is an optional read replica serving the API queries
+	ReplicaConnectionString string `yaml:"replica_connection_string" toml:"replica_connection_string"`
+	// the pool settings apply to the primary and the replica each; 0 means no limit
+	MaxOpenConns    int           `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time"`
+}
+
+type TracerConfig struct {
+	// RPCEndpoint is the chain node to trace blocks from
+	RPCEndpoint     string        `yaml:"rpc_endpoint" toml:"rpc_endpoint"`
+	CallTimeout     time.Duration `yaml:"call_timeout" toml:"call_timeout"`
+	PollingInterval time.Duration `yaml:"polling_interval" toml:"polling_interval"`
+	LeaderElection  bool          `yaml:"leader_election" toml:"leader_election"`
+	// Analyze detects MEV, e.g. sandwiches, from the receipts of each block
+	Analyze bool `yaml:"analyze" toml:"analyze"`
+	// PricesFile is a CSV file of historical ETH/USD prices, to value MEV in USD
+	PricesFile string `yaml:"prices_file" toml:"prices_file"`
+}
+
+type ServerConfig struct {
+	ListenAddr               string        `yaml:"listen_addr" toml:"listen_addr"`
+	RPCPath                  string        `yaml:"rpc_path" toml:"rpc_path"`
+	Pprof                    bool          `yaml:"pprof" toml:"pprof"`
+	DrainDuration            time.Duration `yaml:"drain_duration" toml:"drain_duration"`
+	GracefulShutdownDuration time.Duration `yaml:"graceful_shutdown_duration" toml:"graceful_shutdown_duration"`
+	ReadTimeout              time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout             time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+	MaxBlockLag              uint64        `yaml:"max_block_lag" toml:"max_block_lag"`
+	// AdminToken enables the admin JSON-RPC methods, e.g. to edit labels
+	AdminToken string `yaml:"admin_token" toml:"admin_token"`
+	// LabelsFile is a CSV or JSON file of address labels, imported on start
+	LabelsFile string `yaml:"labels_file" toml:"labels_file"`
+}
+
+type MetricsConfig struct {
+	Addr string `yaml:"addr" toml:"addr"`
+}
+
+type TracingConfig struct {
+	Exporter     string  `yaml:"exporter" toml:"exporter"`
+	OTLPEndpoint string  `yaml:"otlp_endpoint" toml:"otlp_endpoint"`
+	OTLPInsecure bool    `yaml:"otlp_insecure" toml:"otlp_insecure"`
+	File         string  `yaml:"file" toml:"file"`
+	SampleRatio  float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+}
+
+// Default returns the config used if neither a file, env vars nor flags set a value
+func Default() *Config {
+	return &Config{
+		Log: LogConfig{
+			Service: "MEV-Block-Tracer",
+		},
+		DB: DBConfig{
+			QueryTimeout: database.DefaultQueryTimeout,
+			TablePrefix:  vars.DefaultTablePrefix,
+			TableSuffix:  vars.DefaultTableSuffix,
+			MaxOpenConns: database.DefaultMaxOpenConns,
+			MaxIdleConns: database.DefaultMaxIdleConns,
+		},
+		Tracer: TracerConfig{
+			CallTimeout:     blocktrace.DefaultCallTimeout,
+			PollingInterval: blocktrace.DefaultPollingInterval,
+			Analyze:         true,
+		},
+		Server: ServerConfig{
+			ListenAddr:               "0.0.0.0:8080",
+			RPCPath:                  "/",
+			DrainDuration:            45 * time.Second,
+			GracefulShutdownDuration: 30 * time.Second,
+			ReadTimeout:              60 * time.Second,
+			WriteTimeout:             30 * time.Second,
+			MaxBlockLag:              httpserver.DefaultMaxBlockLag,
+		},
+		Metrics: MetricsConfig{
+			Addr: "127.0.0.1:8090",
+		},
+		Tracing: TracingConfig{
+			Exporter:    tracing.ExporterNone,
+			File:        "traces.json",
+			SampleRatio: 1.0,
+		},
+	}
+}
+
+// Load reads the config file at path on top of the defaults.
+// The format is determined by the file extension; settings missing in the file keep their default.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("%w: unknown keys in %s: %v", ErrInvalidConfig, path, undecoded)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+	}
+	return cfg, nil
+}
+
+// Validate checks the settings shared by all commands
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidConfig}, args...)...))
+		}
+	}
+	check(c.DB.QueryTimeout >= 0, "db.query_timeout must not be negative")
+	check(c.DB.TablePrefix != "", "db.table_prefix must be set")
+	check(c.DB.MaxOpenConns >= 0, "db.max_open_conns must not be negative")
+	check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns must not be negative")
+	check(c.DB.ConnMaxLifetime >= 0, "db.conn_max_lifetime must not be negative")
+	check(c.DB.ConnMaxIdleTime >= 0, "db.conn_max_idle_time must not be negative")
+	check(c.Tracer.CallTimeout > 0, "tracer.call_timeout must be positive")
+	check(c.Tracer.PollingInterval > 0, "tracer.polling_interval must be positive")
+	check(c.Server.ListenAddr != "", "server.listen_addr must be set")
+	check(strings.HasPrefix(c.Server.RPCPath, "/"), "server.rpc_path must start with /")
+	check(c.Server.AdminToken == "" || c.Server.RPCPath != httpserver.AdminRPCPath,
+		"server.rpc_path must not be %s if server.admin_token is set", httpserver.AdminRPCPath)
+	check(c.Server.DrainDuration >= 0, "server.drain_duration must not be negative")
+	check(c.Server.GracefulShutdownDuration > 0, "server.graceful_shutdown_duration must be positive")
+	check(c.Server.ReadTimeout > 0, "server.read_timeout must be positive")
+	check(c.Server.WriteTimeout > 0, "server.write_timeout must be positive")
+	switch c.Tracing.Exporter {
+	case "", tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout:
+	case tracing.ExporterFile:
+		check(c.Tracing.File != "", "tracing.file must be set for the file exporter")
+	default:
+		check(false, "unknown tracing.exporter %q", c.Tracing.Exporter)
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+	return errors.Join(errs...)
+}
+
+// ValidateDB checks the settings needed by commands using the database
+func (c *Config) ValidateDB() error {
+	if c.DB.ConnectionString == "" {
+		return fmt.Errorf("%w: db.connection_string must be set", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// ValidateTracer checks the settings needed by commands tracing the chain
+func (c *Config) ValidateTracer() error {
+	if c.Tracer.RPCEndpoint == "" {
+		return fmt.Errorf("%w: tracer.rpc_endpoint must be set", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// redactedPassword replaces passwords in printed configs; it is what url.URL.Redacted uses
+const redactedPassword = "xxxxx"
+
+// dsnPassword matches the password of a key=value DSN, quoted or not
+var dsnPassword = regexp.MustCompile(`(\bpassword\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+
+// Redacted returns a copy of the config with the passwords of the DB connection strings
+// and the admin token masked, to print it e.g. into tickets and logs
+func (c *Config) Redacted() *Config {
+	r := *c
+	r.DB.ConnectionString = redactDSN(c.DB.ConnectionString)
+	r.DB.ReplicaConnectionString = redactDSN(c.DB.ReplicaConnectionString)
+	if c.Server.AdminToken != "" {
+		r.Server.AdminToken = redactedPassword
+	}
+	return &r
+}
+
+// redactDSN masks the password of a postgres URL or key=value DSN
+func redactDSN(dsn string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			// we can't tell which part is the password
+			return redactedPassword
+		}
+		return u.Redacted()
+	}
+	return dsnPassword.ReplaceAllString(dsn, "${1}"+redactedPassword)
+}
+
+// Marshal encodes the config in the given format ("yaml" or "toml")
+func (c *Config) Marshal(format string) ([]byte, error) {
+	switch format {
+	case "yaml", "yml":
+		return yaml.Marshal(c)
+	case "toml":
+		var sb strings.Builder
+		if err := toml.NewEncoder(&sb).Encode(c); err != nil {
+			return nil, err
+		}
+		return []byte(sb.String()), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": `
+db:
+  connection_string: postgres://localhost/mev
+  query_timeout: 3s
+tracer:
+  polling_interval: 2s
+  leader_election: true
+`,
+		"config.toml": `
+[db]
+connection_string = "postgres://localhost/mev"
+query_timeout = "3s"
+
+[tracer]
+polling_interval = "2s"
+leader_election = true
+`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := Load(writeConfig(t, name, content))
+			require.NoError(t, err)
+
+			expected := Default()
+			expected.DB.ConnectionString = "postgres://localhost/mev"
+			expected.DB.QueryTimeout = 3 * time.Second
+			expected.Tracer.PollingInterval = 2 * time.Second
+			expected.Tracer.LeaderElection = true
+			require.Equal(t, expected, cfg)
+			require.NoError(t, cfg.Validate())
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	_, err := Load(writeConfig(t, "config.yaml", "db:\n  conection_string: x\n"))
+	require.ErrorContains(t, err, "conection_string")
+
+	_, err = Load(writeConfig(t, "config.toml", "[db]\nconection_string = \"x\"\n"))
+	require.ErrorIs(t, err, ErrInvalidConfig)
+
+	_, err = Load(writeConfig(t, "config.json", "{}"))
+	require.ErrorIs(t, err, ErrUnknownFormat)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	require.NoError(t, cfg.Validate())
+	// the tracer can be required to be at the chain head
+	cfg.Server.MaxBlockLag = 0
+	require.NoError(t, cfg.Validate())
+	require.ErrorIs(t, cfg.ValidateDB(), ErrInvalidConfig)
+	require.ErrorIs(t, cfg.ValidateTracer(), ErrInvalidConfig)
+
+	cfg.Tracer.PollingInterval = 0
+	cfg.Server.RPCPath = "rpc"
+	cfg.Tracing.Exporter = "jaeger"
+	cfg.DB.MaxOpenConns = -1
+	cfg.Server.AdminToken = "token"
+	err := cfg.Validate()
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	require.ErrorContains(t, err, "tracer.polling_interval")
+	require.ErrorContains(t, err, "server.rpc_path")
+	require.ErrorContains(t, err, "tracing.exporter")
+	require.ErrorContains(t, err, "db.max_open_conns")
+
+	cfg = Default()
+	cfg.Server.AdminToken = "token"
+	cfg.Server.RPCPath = "/admin"
+	require.ErrorContains(t, cfg.Validate(), "server.rpc_path")
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	cfg := Default()
+	cfg.DB.ConnectionString = "postgres://localhost/mev"
+	cfg.Tracer.CallTimeout = 7 * time.Second
+
+	for _, format := range []string{"yaml", "toml"} {
+		out, err := cfg.Marshal(format)
+		require.NoError(t, err)
+		loaded, err := Load(writeConfig(t, "config."+format, string(out)))
+		require.NoError(t, err)
+		require.Equal(t, cfg, loaded)
+	}
+
+	_, err := cfg.Marshal("json")
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+// TestRedacted() tests that printed configs don't contain the DB passwords nor the admin token
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.DB.ConnectionString = "postgres://u:secretpw@h/db?sslmode=disable"
+	cfg.DB.ReplicaConnectionString = "host=replica user=u password='secret pw' dbname=db"
+	cfg.Server.AdminToken = "secret-token"
+
+	redacted := cfg.Redacted()
+	require.Equal(t, "xxxxx", redacted.Server.AdminToken)
+	require.Empty(t, Default().Redacted().Server.AdminToken)
+	require.Equal(t, "postgres://u:xxxxx@h/db?sslmode=disable", redacted.DB.ConnectionString)
+	require.Equal(t, "host=replica user=u password=xxxxx dbname=db", redacted.DB.ReplicaConnectionString)
+	// the config itself is unchanged
+	require.Equal(t, "postgres://u:secretpw@h/db?sslmode=disable", cfg.DB.ConnectionString)
+	for _, format := range []string{"yaml", "toml"} {
+		out, err := redacted.Marshal(format)
+		require.NoError(t, err)
+		require.NotContains(t, string(out), "secret")
+	}
+
+	require.Equal(t, "host=h password=xxxxx", redactDSN("host=h password=secretpw"))
+	require.Equal(t, "postgres://h/db", redactDSN("postgres://h/db"))
+	require.Equal(t, "xxxxx", redactDSN("postgres://u:%zz@h/db"))
+}
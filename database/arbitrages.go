@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database/vars"
+	"github.com/jmoiron/sqlx"
+)
+
+// arbitrageColumns are the columns scanned by scanArbitrage, after the txhash
+const arbitrageColumns = `path, pools, profit_token, profit, bribe, bribe_ratio`
+
+// saveArbitrages inserts the arbitrages of the txs of a block as part of the transaction saving it.
+// The transfers of the same tx share their arbitrage, which is stored once.
+func saveArbitrages(ctx context.Context, tx *sqlx.Tx, blockID, blockNumber uint64, txs []*MEVTransaction) error {
+	insert := `INSERT INTO ` + vars.TableArbitrages + ` (block_id, blocknumber, txhash, ` + arbitrageColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT DO NOTHING`
+	for _, mevTx := range txs {
+		arb := mevTx.Arbitrage
+		if arb == nil {
+			continue
+		}
+		var ratio sql.NullFloat64
+		if arb.BribeRatio != nil {
+			ratio = sql.NullFloat64{Float64: *arb.BribeRatio, Valid: true}
+		}
+		if _, err := tx.ExecContext(ctx, insert, blockID, blockNumber, mevTx.TXHash,
+			hexArray[common.Address](arb.Path), hexArray[common.Address](arb.Pools), arb.ProfitToken, arb.Profit.String(),
+			arb.Bribe.String(), ratio); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanArbitrage scans the txhash and arbitrageColumns of a row
+func scanArbitrage(row interface{ Scan(dest ...any) error }) (common.Hash, *Arbitrage, error) {
+	var (
+		txHash        common.Hash
+		arb           Arbitrage
+		profit, bribe string
+		ratio         sql.NullFloat64
+	)
+	if err := row.Scan(&txHash, (*hexArray[common.Address])(&arb.Path), (*hexArray[common.Address])(&arb.Pools),
+		&arb.ProfitToken, &profit, &bribe, &ratio); err != nil {
+		return "", nil, err
+	}
+	arb.Profit = parseNumeric(profit)
+	arb.Bribe = parseNumeric(bribe)
+	if ratio.Valid {
+		arb.BribeRatio = &ratio.Float64
+	}
+	return txHash, &arb, nil
+}
+
+// attachArbitrages loads the arbitrages matching cond and attaches them to the transfers of their tx
+func (s *DatabaseService) attachArbitrages(ctx context.Context, txs []*MEVTransaction, cond string, arg any) error {
+	if len(txs) == 0 {
+		return nil
+	}
+	sel := `SELECT txhash, ` + arbitrageColumns + ` FROM ` + vars.TableArbitrages + ` WHERE ` + cond
+	rows, err := s.reader().QueryContext(ctx, sel, arg)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		txHash, arb, err := scanArbitrage(rows)
+		if err != nil {
+			return wrapError(err)
+		}
+		for _, tx := range txs {
+			if tx.TXHash == txHash {
+				tx.Arbitrage = arb
+			}
+		}
+	}
+	return wrapError(rows.Err())
+}
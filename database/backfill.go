@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/holisticode/mev-rpc/database/vars"
+)
+
+// status of a backfill shard
+const (
+	ShardPending = "pending"
+	ShardClaimed = "claimed"
+	ShardDone    = "done"
+)
+
+// BackfillShard is a range of blocks (both inclusive) claimed by a backfill worker
+type BackfillShard struct {
+	ID        uint64
+	FromBlock uint64
+	ToBlock   uint64
+}
+
+// CreateBackfillShards splits the range from..to (both inclusive) into shards of shardSize blocks.
+// Shards which already exist are left untouched, so that multiple workers can create the same range,
+// except for completed shards with failed blocks, which are set back to pending to be retried.
+// It returns the number of shards created or set back to pending.
+func (s *DatabaseService) CreateBackfillShards(ctx context.Context, from, to, shardSize uint64) (created int, err error) {
+	ctx, span := startSpan(ctx, "CreateBackfillShards")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if shardSize == 0 || from > to {
+		return 0, fmt.Errorf("%w: invalid shard range %d-%d, size %d", ErrInvalidInput, from, to, shardSize)
+	}
+	insert := `INSERT INTO ` + vars.TableBackfillShards + ` (from_block, to_block) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	tx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	for start := from; start <= to; start += shardSize {
+		end := min(start+shardSize-1, to)
+		res, err := tx.ExecContext(ctx, insert, start, end)
+		if err != nil {
+			return 0, wrapError(err)
+		}
+		n, _ := res.RowsAffected()
+		created += int(n)
+		// avoid overflowing at the end of the uint64 range
+		if end == to {
+			break
+		}
+	}
+	retry := `UPDATE ` + vars.TableBackfillShards + ` SET status = $1
+		WHERE status = $2 AND failed_blocks > 0 AND from_block >= $3 AND to_block <= $4`
+	res, err := tx.ExecContext(ctx, retry, ShardPending, ShardDone, from, to)
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	n, _ := res.RowsAffected()
+	created += int(n)
+	return created, wrapError(tx.Commit())
+}
+
+// ClaimBackfillShard leases the lowest pending shard within from..to to worker, or such a shard whose lease
+// expired because its worker crashed. Shards left by backfills of other ranges are not handed out.
+// It returns ErrNotFound if there is no shard left to claim.
+func (s *DatabaseService) ClaimBackfillShard(
+	ctx context.Context,
+	from, to uint64,
+	worker string,
+	lease time.Duration,
+) (shard *BackfillShard, err error) {
+	ctx, span := startSpan(ctx, "ClaimBackfillShard")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	// SKIP LOCKED lets concurrent workers claim different shards without waiting on each other
+	claim := `UPDATE ` + vars.TableBackfillShards + `
+		SET status = $1, worker = $2, leased_until = now() + $3 * interval '1 millisecond', attempts = attempts + 1
+		WHERE id = (
+			SELECT id FROM ` + vars.TableBackfillShards + `
+			WHERE (status = $4 OR (status = $1 AND leased_until < now()))
+				AND from_block >= $5 AND to_block <= $6
+			ORDER BY from_block LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, from_block, to_block`
+	shard = &BackfillShard{}
+	row := s.DB.QueryRowContext(ctx, claim, ShardClaimed, worker, lease.Milliseconds(), ShardPending, from, to)
+	if err := row.Scan(&shard.ID, &shard.FromBlock, &shard.ToBlock); err != nil {
+		return nil, wrapError(err)
+	}
+	return shard, nil
+}
+
+// RenewBackfillLease extends the lease of a shard claimed by worker.
+// It returns ErrNotFound if the worker doesn't hold the shard anymore.
+func (s *DatabaseService) RenewBackfillLease(ctx context.Context, id uint64, worker string, lease time.Duration) (err error) {
+	ctx, span := startSpan(ctx, "RenewBackfillLease")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	renew := `UPDATE ` + vars.TableBackfillShards + `
+		SET leased_until = now() + $1 * interval '1 millisecond'
+		WHERE id = $2 AND worker = $3 AND status = $4`
+	return s.updateShard(ctx, renew, lease.Milliseconds(), id, worker, ShardClaimed)
+}
+
+// CompleteBackfillShard marks a shard claimed by worker as done, recording the number of blocks which failed.
+// It returns ErrNotFound if the worker doesn't hold the shard anymore.
+func (s *DatabaseService) CompleteBackfillShard(ctx context.Context, id uint64, worker string, failedBlocks uint64) (err error) {
+	ctx, span := startSpan(ctx, "CompleteBackfillShard")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	complete := `UPDATE ` + vars.TableBackfillShards + `
+		SET status = $1, failed_blocks = $2, leased_until = NULL
+		WHERE id = $3 AND worker = $4 AND status = $5`
+	return s.updateShard(ctx, complete, ShardDone, failedBlocks, id, worker, ShardClaimed)
+}
+
+// updateShard executes an update on a single shard, returning ErrNotFound if no shard matched
+func (s *DatabaseService) updateShard(ctx context.Context, query string, args ...any) error {
+	res, err := s.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return wrapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return wrapError(err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_BackfillShards() tests creating, claiming, re-leasing and completing shards
+func Test_BackfillShards(t *testing.T) {
+	db := resetDatabase(t)
+	ctx := t.Context()
+
+	_, err := db.CreateBackfillShards(ctx, 10, 1, 5)
+	require.ErrorIs(t, err, ErrInvalidInput)
+
+	// 100..124 is split into 3 shards, the last one being shorter
+	created, err := db.CreateBackfillShards(ctx, 100, 124, 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, created)
+	// creating the same range again is a no-op
+	created, err = db.CreateBackfillShards(ctx, 100, 124, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, created)
+
+	// shards are claimed in block order
+	first, err := db.ClaimBackfillShard(ctx, 100, 124, "w1", time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), first.FromBlock)
+	require.Equal(t, uint64(109), first.ToBlock)
+	second, err := db.ClaimBackfillShard(ctx, 100, 124, "w2", time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, uint64(110), second.FromBlock)
+	third, err := db.ClaimBackfillShard(ctx, 100, 124, "w3", time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, uint64(124), third.ToBlock)
+
+	// w2's lease expires, so its shard is handed out again
+	time.Sleep(10 * time.Millisecond)
+	released, err := db.ClaimBackfillShard(ctx, 100, 124, "w1", time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, second.ID, released.ID)
+	require.ErrorIs(t, db.RenewBackfillLease(ctx, second.ID, "w2", time.Hour), ErrNotFound)
+	require.ErrorIs(t, db.CompleteBackfillShard(ctx, second.ID, "w2", 0), ErrNotFound)
+
+	// nothing left to claim
+	_, err = db.ClaimBackfillShard(ctx, 100, 124, "w2", time.Hour)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, db.RenewBackfillLease(ctx, first.ID, "w1", time.Hour))
+	for _, s := range []*BackfillShard{first, released} {
+		require.NoError(t, db.CompleteBackfillShard(ctx, s.ID, "w1", 0))
+	}
+	require.NoError(t, db.CompleteBackfillShard(ctx, third.ID, "w3", 1))
+	// completed shards are not handed out again
+	_, err = db.ClaimBackfillShard(ctx, 100, 124, "w2", time.Hour)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// re-running the range retries the shard with failed blocks
+	created, err = db.CreateBackfillShards(ctx, 100, 124, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, created)
+	retried, err := db.ClaimBackfillShard(ctx, 100, 124, "w2", time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, third.ID, retried.ID)
+	require.NoError(t, db.CompleteBackfillShard(ctx, retried.ID, "w2", 0))
+	created, err = db.CreateBackfillShards(ctx, 100, 124, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, created)
+	_, err = db.ClaimBackfillShard(ctx, 100, 124, "w2", time.Hour)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// shards of a backfill of another range are only handed out to that backfill
+	created, err = db.CreateBackfillShards(ctx, 50, 149, 50)
+	require.NoError(t, err)
+	require.Equal(t, 2, created)
+	_, err = db.ClaimBackfillShard(ctx, 100, 124, "w2", time.Hour)
+	require.ErrorIs(t, err, ErrNotFound)
+	other, err := db.ClaimBackfillShard(ctx, 50, 149, "w2", time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, uint64(50), other.FromBlock)
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database/vars"
+	"github.com/jmoiron/sqlx"
+)
+
+// bundleColumns are the columns scanned by scanBundle
+const bundleColumns = `blocknumber, position, txhashes, payment`
+
+// saveBundles inserts the bundles of a block as part of the transaction saving it
+func saveBundles(ctx context.Context, tx *sqlx.Tx, blockID uint64, bundles []*Bundle) error {
+	insert := `INSERT INTO ` + vars.TableBundles + ` (block_id, ` + bundleColumns + `) VALUES ($1, $2, $3, $4, $5)`
+	for _, b := range bundles {
+		if _, err := tx.ExecContext(ctx, insert, blockID, b.BlockNumber, b.Position,
+			hexArray[common.Hash](b.TxHashes), b.Payment.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanBundle scans the bundleColumns of a row
+func scanBundle(row interface{ Scan(dest ...any) error }) (*Bundle, error) {
+	var (
+		b       Bundle
+		payment string
+	)
+	if err := row.Scan(&b.BlockNumber, &b.Position, (*hexArray[common.Hash])(&b.TxHashes), &payment); err != nil {
+		return nil, err
+	}
+	b.Payment = parseNumeric(payment)
+	return &b, nil
+}
+
+// getBundles returns the bundles of a block, in block order
+func (s *DatabaseService) getBundles(ctx context.Context, blockID uint64) ([]*Bundle, error) {
+	sel := `SELECT ` + bundleColumns + ` FROM ` + vars.TableBundles + ` WHERE block_id = ($1) ORDER BY position`
+	rows, err := s.reader().QueryContext(ctx, sel, blockID)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+	var bundles []*Bundle
+	for rows.Next() {
+		b, err := scanBundle(rows)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		bundles = append(bundles, b)
+	}
+	return bundles, wrapError(rows.Err())
+}
+
+// GetBundle returns the bundle containing the tx txhash.
+// Returns ErrNotFound if the tx is not part of a stored bundle.
+func (s *DatabaseService) GetBundle(ctx context.Context, txhash string) (bundle *Bundle, err error) {
+	ctx, span := startSpan(ctx, "GetBundle")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	hash, err := ParseHash(txhash)
+	if err != nil {
+		return nil, err
+	}
+	// the array is matched with @> to use the GIN index
+	sel := `SELECT ` + bundleColumns + ` FROM ` + vars.TableBundles + ` WHERE txhashes @> ARRAY[$1]::text[] LIMIT 1`
+	bundle, err = scanBundle(s.reader().QueryRowContext(ctx, sel, hash))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return bundle, nil
+}
@@ -0,0 +1,114 @@
+package database
+
+import "github.com/holisticode/mev-rpc/common"
+
+// Addresses are stored in lower case; the methods below return copies with the
+// addresses in their EIP-55 checksummed encoding, for clients which ask for it.
+// Hashes are not checksummed, so they are left as they are.
+
+// Checksummed returns a copy of the block with checksummed addresses, including its txs and MEV
+func (b *MEVBlock) Checksummed() *MEVBlock {
+	c := *b
+	c.Miner = checksum(b.Miner)
+	c.MinerLabel = b.MinerLabel.checksummed()
+	c.MEVTransactions = make([]*MEVTransaction, 0, len(b.MEVTransactions))
+	for _, tx := range b.MEVTransactions {
+		c.MEVTransactions = append(c.MEVTransactions, tx.Checksummed())
+	}
+	c.Sandwiches = nil
+	for _, s := range b.Sandwiches {
+		c.Sandwiches = append(c.Sandwiches, s.Checksummed())
+	}
+	c.Liquidations = nil
+	for _, l := range b.Liquidations {
+		c.Liquidations = append(c.Liquidations, l.Checksummed())
+	}
+	c.TokenPayments = nil
+	for _, p := range b.TokenPayments {
+		cp := *p
+		cp.From = checksum(p.From)
+		cp.Token = checksum(p.Token)
+		c.TokenPayments = append(c.TokenPayments, &cp)
+	}
+	c.TokenTotals = checksumTotals(b.TokenTotals)
+	return &c
+}
+
+// Checksummed returns a copy of the tx with checksummed addresses, including its arbitrage
+func (tx *MEVTransaction) Checksummed() *MEVTransaction {
+	c := *tx
+	c.From = checksum(tx.From)
+	c.To = checksum(tx.To)
+	c.FromLabel = tx.FromLabel.checksummed()
+	c.ToLabel = tx.ToLabel.checksummed()
+	if tx.Arbitrage != nil {
+		arb := *tx.Arbitrage
+		arb.Path = checksumAll(arb.Path)
+		arb.Pools = checksumAll(arb.Pools)
+		arb.ProfitToken = checksum(arb.ProfitToken)
+		c.Arbitrage = &arb
+	}
+	return &c
+}
+
+// Checksummed returns a copy of the sandwich with checksummed addresses
+func (s *Sandwich) Checksummed() *Sandwich {
+	c := *s
+	c.Attacker = checksum(s.Attacker)
+	c.Pool = checksum(s.Pool)
+	c.ProfitToken = checksum(s.ProfitToken)
+	c.Victims = checksumAll(s.Victims)
+	return &c
+}
+
+// Checksummed returns a copy of the liquidation with checksummed addresses
+func (l *Liquidation) Checksummed() *Liquidation {
+	c := *l
+	c.Liquidator = checksum(l.Liquidator)
+	c.Borrower = checksum(l.Borrower)
+	c.CollateralAsset = checksum(l.CollateralAsset)
+	c.DebtAsset = checksum(l.DebtAsset)
+	return &c
+}
+
+// Checksummed returns a copy of the stats with checksummed addresses
+func (m *MinerStats) Checksummed() *MinerStats {
+	c := *m
+	c.Miner = checksum(m.Miner)
+	c.TokenTotals = checksumTotals(m.TokenTotals)
+	return &c
+}
+
+func checksumTotals(totals []*TokenTotal) []*TokenTotal {
+	var out []*TokenTotal
+	for _, t := range totals {
+		ct := *t
+		ct.Token = checksum(t.Token)
+		out = append(out, &ct)
+	}
+	return out
+}
+
+func (l *Label) checksummed() *Label {
+	if l == nil {
+		return nil
+	}
+	c := *l
+	c.Address = checksum(l.Address)
+	return &c
+}
+
+func checksum(address common.Address) common.Address {
+	return common.Address(address.Checksum())
+}
+
+func checksumAll(addresses []common.Address) []common.Address {
+	if addresses == nil {
+		return nil
+	}
+	out := make([]common.Address, 0, len(addresses))
+	for _, a := range addresses {
+		out = append(out, checksum(a))
+	}
+	return out
+}
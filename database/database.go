@@ -2,67 +2,137 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"log/slog"
 	"math/big"
-	"os"
+	"slices"
 	"strings"
+	"time"
 
-	"github.com/holisticode/mev-rpc/database/migrations"
+	"github.com/holisticode/mev-rpc/common"
 	"github.com/holisticode/mev-rpc/database/vars"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
-	migrate "github.com/rubenv/sql-migrate"
+	"github.com/lib/pq"
 )
 
-// LastConsideredBlock is the block number from which we start scanning
-const LastConsideredBlock = 21_000_000
+const (
+	// LastConsideredBlock is the block number from which we start scanning
+	LastConsideredBlock = 21_000_000
+	// DefaultQueryTimeout is the maximum time a single query may take
+	DefaultQueryTimeout = 10 * time.Second
+	// DefaultMaxOpenConns is the default maximum number of open connections per pool
+	DefaultMaxOpenConns = 50
+	// DefaultMaxIdleConns is the default maximum number of idle connections per pool
+	DefaultMaxIdleConns = 10
+)
 
 type DatabaseService struct {
-	DB  *sqlx.DB
-	log *slog.Logger
+	// DB is the primary, which all writes go to
+	DB *sqlx.DB
+	// replica serves the API queries if set
+	replica *sqlx.DB
+	log     *slog.Logger
+	// QueryTimeout bounds every query on top of the caller's context; no timeout if 0
+	QueryTimeout time.Duration
+}
+
+// Opts configures the DatabaseService
+type Opts struct {
+	QueryTimeout time.Duration
+	// SkipMigrations doesn't apply pending migrations on start
+	SkipMigrations bool
+	// Pool configures the connection pools to the primary and the replica; defaults if nil
+	Pool *PoolOpts
+	// ReplicaDSN is an optional read replica, which serves the API queries
+	// (GetMEVTx, GetMEVBlock, GetMinerStats and LatestBlockTime); writes and LatestBlock always go to the primary.
+	ReplicaDSN string
 }
 
-func NewDatabaseService(dsn string, log *slog.Logger) (*DatabaseService, error) {
-	db, err := sqlx.Connect("postgres", dsn)
+// NewDatabaseService connects to the DB and applies pending migrations.
+// If opts is nil, the defaults are used.
+func NewDatabaseService(dsn string, log *slog.Logger, opts *Opts) (*DatabaseService, error) {
+	if opts == nil {
+		opts = &Opts{QueryTimeout: DefaultQueryTimeout}
+	}
+	db, err := Connect(dsn, opts.Pool)
 	if err != nil {
 		return nil, err
 	}
 
-	db.SetMaxOpenConns(50)
-	db.SetMaxIdleConns(10)
-	db.SetConnMaxIdleTime(0)
-
-	// fmt.Println(vars.TableMEVBlocks)
-	if os.Getenv("DB_DONT_APPLY_SCHEMA") == "" {
-		migrate.SetTable(vars.TableMigrations)
-		_, err := migrate.Exec(db.DB, "postgres", migrations.Migrations, migrate.Up)
-		if err != nil {
+	if !opts.SkipMigrations {
+		if _, err := MigrateUp(context.Background(), db, 0); err != nil {
+			_ = db.Close()
 			return nil, err
 		}
 	}
 
-	dbService := &DatabaseService{DB: db, log: log} //nolint:exhaustruct
+	dbService := &DatabaseService{DB: db, log: log, QueryTimeout: opts.QueryTimeout}
+	if opts.ReplicaDSN != "" {
+		if dbService.replica, err = Connect(opts.ReplicaDSN, opts.Pool); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("failed to connect to the read replica: %w", err)
+		}
+	}
 	err = dbService.prepareNamedQueries()
 	return dbService, err
 }
 
 func (s *DatabaseService) Close() error {
-	return s.DB.Close()
+	err := s.DB.Close()
+	if s.replica != nil {
+		err = errors.Join(err, s.replica.Close())
+	}
+	return err
+}
+
+// Ping verifies the connections to the DB, and to the replica if set, are still alive
+func (s *DatabaseService) Ping(ctx context.Context) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	if err := s.DB.PingContext(ctx); err != nil {
+		return err
+	}
+	if s.replica != nil {
+		if err := s.replica.PingContext(ctx); err != nil {
+			return fmt.Errorf("read replica: %w", err)
+		}
+	}
+	return nil
+}
+
+// reader returns the pool to run read-only API queries on: the replica if set, else the primary
+func (s *DatabaseService) reader() *sqlx.DB {
+	if s.replica != nil {
+		return s.replica
+	}
+	return s.DB
+}
+
+// withTimeout applies the per-query timeout to ctx
+func (s *DatabaseService) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.QueryTimeout)
 }
 
 // LatestBlock returns the latest block we stored in our DB
-func (s *DatabaseService) LatestBlock() (uint64, error) {
+func (s *DatabaseService) LatestBlock(ctx context.Context) (lastBlock uint64, err error) {
+	ctx, span := startSpan(ctx, "LatestBlock")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	sel := `SELECT blocknumber from ` + vars.TableMEVBlocks + ` ORDER BY blocknumber DESC LIMIT 1`
-	res := s.DB.QueryRow(sel)
-	var lastBlock uint64
+	res := s.DB.QueryRowContext(ctx, sel)
 	if err := res.Scan(&lastBlock); err != nil {
 		if err == sql.ErrNoRows {
 			return LastConsideredBlock, nil
 		}
-		return LastConsideredBlock, err
+		return LastConsideredBlock, wrapError(err)
 	}
 	if lastBlock < LastConsideredBlock {
 		lastBlock = LastConsideredBlock
@@ -70,130 +140,187 @@ func (s *DatabaseService) LatestBlock() (uint64, error) {
 	return lastBlock, nil
 }
 
+// LatestBlockTime returns the timestamp of the latest block stored, as seen by the API queries;
+// it returns ErrNotFound if no block is stored
+func (s *DatabaseService) LatestBlockTime(ctx context.Context) (blockTime uint64, err error) {
+	ctx, span := startSpan(ctx, "LatestBlockTime")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	sel := `SELECT blocktime FROM ` + vars.TableMEVBlocks + ` ORDER BY blocktime DESC LIMIT 1`
+	if err := s.reader().QueryRowContext(ctx, sel).Scan(&blockTime); err != nil {
+		return 0, wrapError(err)
+	}
+	return blockTime, nil
+}
+
 // OldestBlock is currently unused
-func (s *DatabaseService) OldestBlock() uint64 {
+func (s *DatabaseService) OldestBlock(_ context.Context) uint64 {
 	return 0
 }
 
-// GetMEVBlock returns a block by its number OR its hash
-// Returns an error if the block can not be found
-func (s *DatabaseService) GetMEVBlock(block string) (*MEVBlock, error) {
-	searchCol := "blocknumber"
-	if strings.HasPrefix(block, "0x") {
-		searchCol = "blockhash"
-	}
-	// This SQL code will return a row for each tx associated to the block, which means...
-	sel := `SELECT b.*,t.* from ` + vars.TableMEVBlocks + ` b INNER JOIN ` + vars.TableMEVTxs + ` t ON b.id = t.block_id WHERE b.` + searchCol + ` = ($1)`
-	rows, err := s.DB.Query(sel, block)
+// GetMEVBlock returns a block by its number OR its hash, with its transactions in the order they appear in the block.
+// Returns ErrInvalidInput if block is neither, and ErrNotFound if the block can not be found
+func (s *DatabaseService) GetMEVBlock(ctx context.Context, block string) (mevBlock *MEVBlock, err error) {
+	ctx, span := startSpan(ctx, "GetMEVBlock")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	isHash, number, err := ParseBlockID(block)
 	if err != nil {
 		return nil, err
 	}
+	searchCol := "blocknumber"
+	var searchVal any = number
+	if isHash {
+		searchCol = "blockhash"
+		searchVal = strings.ToLower(block)
+	}
 
-	var txs []*MEVTransaction
+	// a block and its txs are saved in the same DB transaction,
+	// so if the block is found, all of its txs are visible as well
+	selBlock := `SELECT id, blocknumber, blockhash, miner, flashbot, total, blocktime, ethusd, total_usd
+		FROM ` + vars.TableMEVBlocks + ` WHERE ` + searchCol + ` = ($1)`
 	var (
-		bID         uint64
-		blocknumber uint64
-		blockhash   string
-		miner       string
-		flashbot    bool
-		total       string
+		blockID uint64
+		total   string
 	)
+	mevBlock = &MEVBlock{MEVTransactions: []*MEVTransaction{}}
+	if err := s.reader().QueryRowContext(ctx, selBlock, searchVal).Scan(
+		&blockID,
+		&mevBlock.BlockNumber,
+		&mevBlock.BlockHash,
+		&mevBlock.Miner,
+		&mevBlock.IsFlashbotMiner,
+		&total,
+		&mevBlock.Timestamp,
+		&mevBlock.ETHUSD,
+		&mevBlock.TotalMinerValueUSD,
+	); err != nil {
+		return nil, wrapError(err)
+	}
+	mevBlock.TotalMinerValue = parseNumeric(total)
 
-	count := 0
-
-	// TODO: ...that this can and probably should be refactored
-	// Because the block data is being repeated on every row scan
+	selTxs := `SELECT ` + txColumns + ` FROM ` + vars.TableMEVTxs + `
+		WHERE block_id = ($1) ORDER BY position, trace_address, id`
+	rows, err := s.reader().QueryContext(ctx, selTxs, blockID)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
 	for rows.Next() {
-		count++
-		var (
-			tID      uint64
-			blockID  uint64
-			blockNum uint64
-			hash     string
-			from     string
-			to       string
-			value    string
-		)
-		if err := rows.Scan(
-			&bID,
-			&blocknumber,
-			&blockhash,
-			&miner,
-			&flashbot,
-			&total,
-			&tID,
-			&blockID,
-			&blockNum,
-			&hash,
-			&from,
-			&to,
-			&value); err != nil {
-			return nil, err
-		}
-
-		val := new(big.Int)
-		val.SetString(value, 10)
-		tx := &MEVTransaction{
-			BlockNumber: blockNum,
-			TXHash:      hash,
-			From:        from,
-			To:          to,
-			Value:       val,
+		tx, err := scanMEVTx(rows)
+		if err != nil {
+			return nil, wrapError(err)
 		}
-		txs = append(txs, tx)
+		mevBlock.MEVTransactions = append(mevBlock.MEVTransactions, tx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+	if err := s.attachArbitrages(ctx, mevBlock.MEVTransactions, "block_id = ($1)", blockID); err != nil {
+		return nil, err
+	}
+	if err := s.attachLabels(ctx, mevBlock, mevBlock.MEVTransactions); err != nil {
+		return nil, err
 	}
 
-	// if now txs could be found then there's no block either
-	if count == 0 {
-		return nil, sql.ErrNoRows
+	selSandwiches := `SELECT ` + sandwichColumns + ` FROM ` + vars.TableSandwiches + ` WHERE block_id = ($1) ORDER BY id`
+	if mevBlock.Sandwiches, err = s.querySandwiches(ctx, selSandwiches, blockID); err != nil {
+		return nil, err
+	}
+	selLiquidations := `SELECT ` + liquidationColumns + ` FROM ` + vars.TableLiquidations + ` WHERE block_id = ($1) ORDER BY id`
+	if mevBlock.Liquidations, err = s.queryLiquidations(ctx, selLiquidations, blockID); err != nil {
+		return nil, err
 	}
-	tot := new(big.Int)
-	tot.SetString(total, 10)
-	// return the block with its transactions
-	return &MEVBlock{
-		BlockNumber:     blocknumber,
-		BlockHash:       blockhash,
-		MEVTransactions: txs,
-		Miner:           miner,
-		IsFlashbotMiner: flashbot,
-		TotalMinerValue: tot,
-	}, nil
+	if mevBlock.TokenPayments, err = s.getTokenPayments(ctx, blockID); err != nil {
+		return nil, err
+	}
+	mevBlock.TokenTotals = TokenTotals(mevBlock.TokenPayments)
+	if mevBlock.Bundles, err = s.getBundles(ctx, blockID); err != nil {
+		return nil, err
+	}
+	// omitted from the JSON if there are none
+	if len(mevBlock.Sandwiches) == 0 {
+		mevBlock.Sandwiches = nil
+	}
+	if len(mevBlock.Liquidations) == 0 {
+		mevBlock.Liquidations = nil
+	}
+	return mevBlock, nil
 }
 
-// GetMEVTx returns a single tx by its hash
-// If it can't find the tx, it returns an error
-func (s *DatabaseService) GetMEVTx(txhash string) (*MEVTransaction, error) {
-	sel := `SELECT * from ` + vars.TableMEVTxs + ` WHERE txhash = ($1)`
-	row := s.DB.QueryRow(sel, txhash)
+// txColumns are the columns scanned by scanMEVTx
+const txColumns = `blocknumber, txhash, src, dest, value, value_usd, position, trace_address, gas_used`
+
+// scanMEVTx scans the txColumns of a row
+func scanMEVTx(row interface{ Scan(dest ...any) error }) (*MEVTransaction, error) {
 	var (
-		id       uint64
-		blockID  uint64
-		blockNum uint64
-		hash     string
-		from     string
-		to       string
-		value    string
+		tx           MEVTransaction
+		value        string
+		traceAddress pq.Int64Array
 	)
-	if err := row.Scan(&id, &blockID, &blockNum, &hash, &from, &to, &value); err != nil {
+	if err := row.Scan(&tx.BlockNumber, &tx.TXHash, &tx.From, &tx.To, &value, &tx.ValueUSD, &tx.Position, &traceAddress, &tx.GasUsed); err != nil {
 		return nil, err
 	}
-	val := new(big.Int)
-	val.SetString(value, 10)
-	return &MEVTransaction{
-		BlockNumber: blockNum,
-		TXHash:      hash,
-		From:        from,
-		To:          to,
-		Value:       val,
-	}, nil
-}
-
-// SaveMEVBLock saves the block and its transactions to disk in a one to many relationship
-func (s *DatabaseService) SaveMEVBLock(block *MEVBlock, txs []*MEVTransaction) error {
-	insertBlock := `INSERT INTO ` + vars.TableMEVBlocks + `(blocknumber, blockhash, miner, flashbot, total) VALUES ($1, $2, $3, $4, $5) RETURNING id`
-	insertTxs := `INSERT INTO ` + vars.TableMEVTxs + `(block_id, blocknumber, txhash, src, dest, value) VALUES (:block_id, :blocknumber, :txhash, :src, :dest, :value)`
+	tx.Value = parseNumeric(value)
+	tx.TraceAddress = make([]uint64, len(traceAddress))
+	for i, a := range traceAddress {
+		tx.TraceAddress[i] = uint64(a) //nolint:gosec
+	}
+	return &tx, nil
+}
+
+// traceAddressArray converts a trace address for storage
+func traceAddressArray(traceAddress []uint64) pq.Int64Array {
+	arr := make(pq.Int64Array, len(traceAddress))
+	for i, a := range traceAddress {
+		arr[i] = int64(a) //nolint:gosec
+	}
+	return arr
+}
+
+// GetMEVTx returns a single tx by its hash
+// Returns ErrInvalidInput if txhash is not a hash, and ErrNotFound if it can't find the tx
+func (s *DatabaseService) GetMEVTx(ctx context.Context, txhash string) (mevTx *MEVTransaction, err error) {
+	ctx, span := startSpan(ctx, "GetMEVTx")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	hash, err := ParseHash(txhash)
+	if err != nil {
+		return nil, err
+	}
+	sel := `SELECT ` + txColumns + ` FROM ` + vars.TableMEVTxs + ` WHERE txhash = ($1)`
+	mevTx, err = scanMEVTx(s.reader().QueryRowContext(ctx, sel, hash))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if err := s.attachArbitrages(ctx, []*MEVTransaction{mevTx}, "txhash = ($1)", hash); err != nil {
+		return nil, err
+	}
+	if err := s.attachLabels(ctx, nil, []*MEVTransaction{mevTx}); err != nil {
+		return nil, err
+	}
+	return mevTx, nil
+}
+
+// SaveMEVBLock saves the block, its transactions and the MEV detected in it in a one to many relationship
+func (s *DatabaseService) SaveMEVBLock(ctx context.Context, block *MEVBlock, txs []*MEVTransaction) (err error) {
+	ctx, span := startSpan(ctx, "SaveMEVBLock")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	insertBlock := `INSERT INTO ` + vars.TableMEVBlocks + `(blocknumber, blockhash, miner, flashbot, total, blocktime, ethusd, total_usd)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	insertTxs := `INSERT INTO ` + vars.TableMEVTxs + `(block_id, blocknumber, txhash, src, dest, value, value_usd, position, trace_address, gas_used)
+		VALUES (:block_id, :blocknumber, :txhash, :src, :dest, :value, :value_usd, :position, :trace_address, :gas_used)`
 	value := block.TotalMinerValue.String()
-	beginTx, err := s.DB.Beginx()
+	beginTx, err := s.DB.BeginTxx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to initiate begin tx: %w", err)
 	}
@@ -204,7 +331,8 @@ func (s *DatabaseService) SaveMEVBLock(block *MEVBlock, txs []*MEVTransaction) e
 		}
 	}()
 
-	bRes := beginTx.QueryRowx(insertBlock, block.BlockNumber, block.BlockHash, block.Miner, block.IsFlashbotMiner, value)
+	bRes := beginTx.QueryRowxContext(ctx, insertBlock, block.BlockNumber, block.BlockHash, block.Miner, block.IsFlashbotMiner, value, block.Timestamp,
+		block.ETHUSD, block.TotalMinerValueUSD)
 	var blockID uint64
 	err = bRes.Scan(&blockID)
 	if err != nil {
@@ -215,19 +343,40 @@ func (s *DatabaseService) SaveMEVBLock(block *MEVBlock, txs []*MEVTransaction) e
 	for _, tx := range txs {
 		valStr := tx.Value.String()
 		thisTx := map[string]interface{}{
-			"block_id":    blockID,
-			"blocknumber": block.BlockNumber,
-			"txhash":      tx.TXHash,
-			"src":         tx.From,
-			"dest":        tx.To,
-			"value":       valStr,
+			"block_id":      blockID,
+			"blocknumber":   block.BlockNumber,
+			"txhash":        tx.TXHash,
+			"src":           tx.From,
+			"dest":          tx.To,
+			"value":         valStr,
+			"value_usd":     tx.ValueUSD,
+			"position":      tx.Position,
+			"trace_address": traceAddressArray(tx.TraceAddress),
+			"gas_used":      tx.GasUsed,
 		}
 		txMap = append(txMap, thisTx)
 	}
 
-	_, err = beginTx.NamedExec(insertTxs, txMap)
-	if err != nil {
-		return fmt.Errorf("failed to insert transactions into DB: %w", err)
+	// a batch insert needs at least one row
+	if len(txMap) > 0 {
+		if _, err := beginTx.NamedExecContext(ctx, insertTxs, txMap); err != nil {
+			return fmt.Errorf("failed to insert transactions into DB: %w", err)
+		}
+	}
+	if err := saveArbitrages(ctx, beginTx, blockID, block.BlockNumber, txs); err != nil {
+		return fmt.Errorf("failed to insert arbitrages into DB: %w", err)
+	}
+	if err := saveSandwiches(ctx, beginTx, blockID, block.Sandwiches); err != nil {
+		return fmt.Errorf("failed to insert sandwiches into DB: %w", err)
+	}
+	if err := saveLiquidations(ctx, beginTx, blockID, block.Liquidations); err != nil {
+		return fmt.Errorf("failed to insert liquidations into DB: %w", err)
+	}
+	if err := saveTokenPayments(ctx, beginTx, blockID, block.TokenPayments); err != nil {
+		return fmt.Errorf("failed to insert token payments into DB: %w", err)
+	}
+	if err := saveBundles(ctx, beginTx, blockID, block.Bundles); err != nil {
+		return fmt.Errorf("failed to insert bundles into DB: %w", err)
 	}
 
 	if err := beginTx.Commit(); err != nil {
@@ -237,6 +386,183 @@ func (s *DatabaseService) SaveMEVBLock(block *MEVBlock, txs []*MEVTransaction) e
 	return nil
 }
 
+// SaveUSDValues sets the USD values of a stored block and its transactions, e.g. for a block
+// indexed before prices were available. Transactions are matched by hash, position and trace address.
+// It returns ErrNotFound if the block is not stored.
+func (s *DatabaseService) SaveUSDValues(ctx context.Context, block *MEVBlock) (err error) {
+	ctx, span := startSpan(ctx, "SaveUSDValues")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	updateBlock := `UPDATE ` + vars.TableMEVBlocks + ` SET ethusd = $1, total_usd = $2 WHERE blockhash = $3 RETURNING id`
+	updateTx := `UPDATE ` + vars.TableMEVTxs + ` SET value_usd = $1
+		WHERE block_id = $2 AND txhash = $3 AND position = $4 AND trace_address = $5`
+	beginTx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer func() { _ = beginTx.Rollback() }()
+
+	var blockID uint64
+	if err := beginTx.QueryRowContext(ctx, updateBlock, block.ETHUSD, block.TotalMinerValueUSD, block.BlockHash).Scan(&blockID); err != nil {
+		return wrapError(err)
+	}
+	for _, tx := range block.MEVTransactions {
+		if _, err := beginTx.ExecContext(ctx, updateTx, tx.ValueUSD, blockID, tx.TXHash, tx.Position,
+			traceAddressArray(tx.TraceAddress)); err != nil {
+			return wrapError(err)
+		}
+	}
+	return wrapError(beginTx.Commit())
+}
+
+// GetMinerStats aggregates the stored MEV per fee recipient (miner) over the given window.
+// The aggregation is done entirely in SQL; results are ordered by total value, descending.
+func (s *DatabaseService) GetMinerStats(ctx context.Context, window *StatsWindow) (stats []*MinerStats, err error) {
+	ctx, span := startSpan(ctx, "GetMinerStats")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if window == nil {
+		window = &StatsWindow{}
+	}
+	conds := []string{}
+	args := []any{}
+	addCond := func(cond string, arg uint64) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if window.FromBlock > 0 {
+		addCond("blocknumber >= $%d", window.FromBlock)
+	}
+	if window.ToBlock > 0 {
+		addCond("blocknumber <= $%d", window.ToBlock)
+	}
+	if window.FromTime > 0 {
+		addCond("blocktime >= $%d", window.FromTime)
+	}
+	if window.ToTime > 0 {
+		addCond("blocktime <= $%d", window.ToTime)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+	// blocks stored only for their analysis results (e.g. sandwiches) have no MEV paid to the miner
+	paidConds := append(slices.Clone(conds), `EXISTS (SELECT 1 FROM `+vars.TableMEVTxs+` t WHERE t.block_id = m.id)`)
+	paidWhere := " WHERE " + strings.Join(paidConds, " AND ")
+
+	// values are stored as text, so they need to be cast to numeric to be aggregated;
+	// results are cast back to text to be able to parse them without precision loss.
+	// percentile_cont only works on floats, so the median is the mean of the lower and upper
+	// middle values, which are the same for an odd number of blocks; it is truncated to wei.
+	sel := `WITH blocks AS (
+			SELECT id, miner, total::numeric AS total, total_usd FROM ` + vars.TableMEVBlocks + ` m` + paidWhere + `
+		), transfers AS (
+			SELECT t.block_id, COUNT(*) AS cnt FROM ` + vars.TableMEVTxs + ` t
+			INNER JOIN blocks b ON b.id = t.block_id GROUP BY t.block_id
+		)
+		SELECT b.miner,
+			COUNT(*),
+			COALESCE(SUM(tr.cnt), 0),
+			SUM(b.total)::text,
+			((percentile_disc(0.5) WITHIN GROUP (ORDER BY b.total) +
+				percentile_disc(0.5) WITHIN GROUP (ORDER BY b.total DESC)) / 2)::text,
+			MAX(b.total)::text,
+			COALESCE(SUM(b.total) / NULLIF(SUM(SUM(b.total)) OVER (), 0), 0)::float8,
+			SUM(b.total_usd),
+			COUNT(b.total_usd)
+		FROM blocks b LEFT JOIN transfers tr ON tr.block_id = b.id
+		GROUP BY b.miner
+		ORDER BY SUM(b.total) DESC, b.miner`
+	rows, err := s.reader().QueryContext(ctx, sel, args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	stats = make([]*MinerStats, 0)
+	for rows.Next() {
+		var (
+			st                          MinerStats
+			totalStr, medianStr, maxStr string
+		)
+		if err := rows.Scan(&st.Miner, &st.Blocks, &st.Transfers, &totalStr, &medianStr, &maxStr, &st.Share, &st.TotalValueUSD, &st.PricedBlocks); err != nil {
+			return nil, wrapError(err)
+		}
+		st.TotalValue = parseNumeric(totalStr)
+		st.MedianValue = parseNumeric(medianStr)
+		st.MaxValue = parseNumeric(maxStr)
+		stats = append(stats, &st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+	return s.attachMinerTokenTotals(ctx, stats, where, args)
+}
+
+// attachMinerTokenTotals adds the token payments per token to the stats of each miner,
+// over the blocks matching where. Miners only paid in tokens are appended with zero values.
+func (s *DatabaseService) attachMinerTokenTotals(ctx context.Context, stats []*MinerStats, where string, args []any) ([]*MinerStats, error) {
+	sel := `WITH blocks AS (
+			SELECT id, miner FROM ` + vars.TableMEVBlocks + where + `
+		)
+		SELECT b.miner, p.token, MAX(p.decimals), SUM(p.amount::numeric)::text, COUNT(*)
+		FROM ` + vars.TableTokenPayments + ` p INNER JOIN blocks b ON b.id = p.block_id
+		GROUP BY b.miner, p.token
+		ORDER BY b.miner, SUM(p.amount::numeric) DESC, p.token`
+	rows, err := s.reader().QueryContext(ctx, sel, args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	byMiner := map[common.Address]*MinerStats{}
+	for _, st := range stats {
+		byMiner[st.Miner] = st
+	}
+	for rows.Next() {
+		var (
+			miner    common.Address
+			amount   string
+			total    TokenTotal
+			decimals sql.NullInt16
+		)
+		if err := rows.Scan(&miner, &total.Token, &decimals, &amount, &total.Payments); err != nil {
+			return nil, wrapError(err)
+		}
+		total.Decimals = parseDecimals(decimals)
+		total.Amount = parseNumeric(amount)
+		st, ok := byMiner[miner]
+		if !ok {
+			st = &MinerStats{
+				Miner:       miner,
+				TotalValue:  new(big.Int),
+				MedianValue: new(big.Int),
+				MaxValue:    new(big.Int),
+			}
+			byMiner[miner] = st
+			stats = append(stats, st)
+		}
+		st.TokenTotals = append(st.TokenTotals, &total)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+	return stats, nil
+}
+
+// parseNumeric parses a postgres numeric (cast to text) into a big.Int
+func parseNumeric(s string) *big.Int {
+	// integer numerics can still carry a fractional part after aggregation
+	s, _, _ = strings.Cut(s, ".")
+	val := new(big.Int)
+	val.SetString(s, 10)
+	return val
+}
+
 func (s *DatabaseService) prepareNamedQueries() (err error) {
 	return nil
 }
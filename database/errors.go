@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/lib/pq"
+)
+
+// Typed errors returned by the storage, so that callers can tell apart
+// missing data, bad input and a DB outage without looking at driver errors
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrUnavailable  = errors.New("database unavailable")
+)
+
+// ValidateHash checks that s is a 0x-prefixed 32 bytes hex string (tx or block hash), in any case
+func ValidateHash(s string) error {
+	_, err := ParseHash(s)
+	return err
+}
+
+// ValidateAddress checks that s is a 0x-prefixed 20 bytes hex string, in any case
+func ValidateAddress(s string) error {
+	_, err := ParseAddress(s)
+	return err
+}
+
+// ParseHash validates a hash from a client, and normalizes it to lower case
+func ParseHash(s string) (common.Hash, error) {
+	h, err := common.ParseHash(s)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	return h, nil
+}
+
+// ParseAddress validates an address from a client, and normalizes it to lower case
+func ParseAddress(s string) (common.Address, error) {
+	a, err := common.ParseAddress(s)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	return a, nil
+}
+
+// ParseBlockID checks that block is either a block hash or a decimal block number.
+// It returns whether it is a hash, and the block number if it isn't.
+func ParseBlockID(block string) (isHash bool, number uint64, err error) {
+	if strings.HasPrefix(block, "0x") {
+		return true, 0, ValidateHash(block)
+	}
+	number, err = strconv.ParseUint(block, 10, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("%w: block must be a block hash or a decimal block number", ErrInvalidInput)
+	}
+	return false, number, nil
+}
+
+// wrapError classifies DB errors into the typed errors of this package.
+// The original error is kept in the chain.
+func wrapError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case isConnectionError(err):
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	return err
+}
+
+// isConnectionError returns true if err is caused by not being able to reach the DB
+// (as opposed to an error in the query itself)
+func isConnectionError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		// connection exception, insufficient resources, operator intervention
+		case "08", "53", "57":
+			return true
+		}
+	}
+	return false
+}
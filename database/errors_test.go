@@ -0,0 +1,60 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateHash(t *testing.T) {
+	require.NoError(t, ValidateHash(testBlockHash))
+	require.ErrorIs(t, ValidateHash("0x1234"), ErrInvalidInput)
+	require.ErrorIs(t, ValidateHash(testBlockHash[2:]), ErrInvalidInput)
+	require.ErrorIs(t, ValidateHash(testBlockHash[:65]+"z"), ErrInvalidInput)
+}
+
+func TestValidateAddress(t *testing.T) {
+	require.NoError(t, ValidateAddress("0xae2fc483527b8ef99eb5d9b44875f005ba1fae13"))
+	require.NoError(t, ValidateAddress("0xAE2FC483527B8EF99EB5D9B44875F005BA1FAE13"))
+	require.ErrorIs(t, ValidateAddress("0x1234"), ErrInvalidInput)
+	require.ErrorIs(t, ValidateAddress(testBlockHash), ErrInvalidInput)
+}
+
+func TestParseBlockID(t *testing.T) {
+	isHash, _, err := ParseBlockID(testBlockHash)
+	require.NoError(t, err)
+	require.True(t, isHash)
+
+	isHash, number, err := ParseBlockID("21000042")
+	require.NoError(t, err)
+	require.False(t, isHash)
+	require.Equal(t, uint64(21_000_042), number)
+
+	for _, invalid := range []string{"", "-1", "0x42", "21000042; DROP TABLE x", "1e6"} {
+		_, _, err := ParseBlockID(invalid)
+		require.ErrorIs(t, err, ErrInvalidInput, invalid)
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	require.NoError(t, wrapError(nil))
+
+	err := wrapError(sql.ErrNoRows)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	err = wrapError(&net.OpError{Op: "dial", Err: errors.New("connection refused")})
+	require.ErrorIs(t, err, ErrUnavailable)
+
+	err = wrapError(&pq.Error{Code: "57P01"}) // admin shutdown
+	require.ErrorIs(t, err, ErrUnavailable)
+
+	// query errors are not classified
+	err = wrapError(&pq.Error{Code: "42P01"}) // undefined table
+	require.NotErrorIs(t, err, ErrUnavailable)
+	require.NotErrorIs(t, err, ErrNotFound)
+}
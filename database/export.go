@@ -0,0 +1,180 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/holisticode/mev-rpc/database/vars"
+)
+
+// ExportTable is a table which can be exported
+type ExportTable string
+
+const (
+	ExportBlocks ExportTable = "blocks"
+	ExportTxs    ExportTable = "txs"
+)
+
+// ColumnType is the type of the values of an exported column
+type ColumnType int
+
+const (
+	ColumnInt ColumnType = iota
+	ColumnFloat
+	ColumnBool
+	ColumnString
+)
+
+// ExportColumn is a column which can be exported
+type ExportColumn struct {
+	Name string
+	Type ColumnType
+	// expr selects the column; blocks are aliased b, txs t
+	expr string
+}
+
+// wei values are exported as decimal strings, so that they don't lose precision
+var exportColumns = map[ExportTable][]ExportColumn{
+	ExportBlocks: {
+		{Name: "blocknumber", Type: ColumnInt, expr: "b.blocknumber"},
+		{Name: "blockhash", Type: ColumnString, expr: "b.blockhash"},
+		{Name: "miner", Type: ColumnString, expr: "b.miner"},
+		{Name: "flashbot", Type: ColumnBool, expr: "b.flashbot"},
+		{Name: "total", Type: ColumnString, expr: "b.total"},
+		{Name: "blocktime", Type: ColumnInt, expr: "b.blocktime"},
+		{Name: "ethusd", Type: ColumnFloat, expr: "b.ethusd"},
+		{Name: "total_usd", Type: ColumnFloat, expr: "b.total_usd"},
+	},
+	ExportTxs: {
+		{Name: "blocknumber", Type: ColumnInt, expr: "t.blocknumber"},
+		{Name: "blocktime", Type: ColumnInt, expr: "b.blocktime"},
+		{Name: "txhash", Type: ColumnString, expr: "t.txhash"},
+		{Name: "src", Type: ColumnString, expr: "t.src"},
+		{Name: "dest", Type: ColumnString, expr: "t.dest"},
+		{Name: "value", Type: ColumnString, expr: "t.value"},
+		{Name: "value_usd", Type: ColumnFloat, expr: "t.value_usd"},
+		{Name: "position", Type: ColumnInt, expr: "t.position"},
+		// e.g. "1.0"; empty for the top level call
+		{Name: "trace_address", Type: ColumnString, expr: "array_to_string(t.trace_address, '.')"},
+		{Name: "gas_used", Type: ColumnInt, expr: "t.gas_used"},
+	},
+}
+
+// ExportColumns returns the columns named, in that order, or all columns of table if names is empty.
+// Returns ErrInvalidInput for an unknown table or column.
+func ExportColumns(table ExportTable, names []string) ([]ExportColumn, error) {
+	all, ok := exportColumns[table]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown table %q", ErrInvalidInput, table)
+	}
+	if len(names) == 0 {
+		return all, nil
+	}
+	columns := make([]ExportColumn, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, c := range all {
+			if c.Name == strings.TrimSpace(name) {
+				columns = append(columns, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: unknown column %q of %s", ErrInvalidInput, name, table)
+		}
+	}
+	return columns, nil
+}
+
+// ExportRows streams the columns of the rows of table in window to fn, ordered by block and position.
+// Values are int64, float64, bool or string, or nil for NULL.
+// The query is not bounded by the query timeout, since an export can take long; cancel ctx to abort it.
+func (s *DatabaseService) ExportRows(
+	ctx context.Context,
+	table ExportTable,
+	columns []ExportColumn,
+	window *StatsWindow,
+	fn func(row []any) error,
+) (err error) {
+	ctx, span := startSpan(ctx, "ExportRows")
+	defer func() { endSpan(span, err) }()
+
+	if window == nil {
+		window = &StatsWindow{}
+	}
+	conds := []string{}
+	args := []any{}
+	addCond := func(cond string, arg uint64) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if window.FromBlock > 0 {
+		addCond("b.blocknumber >= $%d", window.FromBlock)
+	}
+	if window.ToBlock > 0 {
+		addCond("b.blocknumber <= $%d", window.ToBlock)
+	}
+	if window.FromTime > 0 {
+		addCond("b.blocktime >= $%d", window.FromTime)
+	}
+	if window.ToTime > 0 {
+		addCond("b.blocktime <= $%d", window.ToTime)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+	exprs := make([]string, 0, len(columns))
+	for _, c := range columns {
+		exprs = append(exprs, c.expr)
+	}
+	var sel string
+	switch table {
+	case ExportBlocks:
+		sel = `SELECT ` + strings.Join(exprs, ", ") + ` FROM ` + vars.TableMEVBlocks + ` b` + where +
+			` ORDER BY b.blocknumber, b.id`
+	case ExportTxs:
+		sel = `SELECT ` + strings.Join(exprs, ", ") + ` FROM ` + vars.TableMEVTxs + ` t
+			INNER JOIN ` + vars.TableMEVBlocks + ` b ON b.id = t.block_id` + where +
+			` ORDER BY t.blocknumber, t.position, t.trace_address, t.id`
+	default:
+		return fmt.Errorf("%w: unknown table %q", ErrInvalidInput, table)
+	}
+
+	rows, err := s.reader().QueryContext(ctx, sel, args...)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer rows.Close()
+	dest := make([]any, len(columns))
+	for i, c := range columns {
+		switch c.Type {
+		case ColumnInt:
+			dest[i] = new(sql.NullInt64)
+		case ColumnFloat:
+			dest[i] = new(sql.NullFloat64)
+		case ColumnBool:
+			dest[i] = new(sql.NullBool)
+		default:
+			dest[i] = new(sql.NullString)
+		}
+	}
+	row := make([]any, len(columns))
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return wrapError(err)
+		}
+		for i, d := range dest {
+			// Value returns nil for NULL
+			row[i], _ = d.(driver.Valuer).Value()
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return wrapError(rows.Err())
+}
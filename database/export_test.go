@@ -0,0 +1,67 @@
+package database
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportColumns(t *testing.T) {
+	all, err := ExportColumns(ExportTxs, nil)
+	require.NoError(t, err)
+	require.Len(t, all, len(exportColumns[ExportTxs]))
+
+	columns, err := ExportColumns(ExportBlocks, []string{"miner", " blocknumber"})
+	require.NoError(t, err)
+	require.Equal(t, "miner", columns[0].Name)
+	require.Equal(t, "blocknumber", columns[1].Name)
+	require.Equal(t, ColumnInt, columns[1].Type)
+
+	_, err = ExportColumns(ExportBlocks, []string{"txhash"})
+	require.ErrorIs(t, err, ErrInvalidInput)
+	_, err = ExportColumns("sandwiches", nil)
+	require.ErrorIs(t, err, ErrInvalidInput)
+}
+
+// Test_ExportRows() tests that the rows in the window are streamed in block order
+func Test_ExportRows(t *testing.T) {
+	db := resetDatabase(t)
+	price := 2500.5
+	for i, hash := range []string{"1", "2", "3"} {
+		block := createMEVBlock()
+		block.BlockNumber += uint64(i)
+		block.BlockHash = common.Hash("0x" + strings.Repeat(hash, 64))
+		block.Timestamp = 1_730_000_000 + uint64(i)
+		if i == 1 {
+			block.ETHUSD = &price
+		}
+		tx := createMEVTx("0x" + strings.Repeat(hash, 63) + "a")
+		tx.BlockNumber = block.BlockNumber
+		tx.TraceAddress = []uint64{1, 0}
+		require.NoError(t, db.SaveMEVBLock(t.Context(), block, []*MEVTransaction{tx}))
+	}
+
+	columns, err := ExportColumns(ExportBlocks, []string{"blocknumber", "ethusd", "flashbot"})
+	require.NoError(t, err)
+	var rows [][]any
+	collect := func(row []any) error {
+		rows = append(rows, append([]any{}, row...))
+		return nil
+	}
+	require.NoError(t, db.ExportRows(t.Context(), ExportBlocks, columns, &StatsWindow{FromBlock: 21_000_043}, collect))
+	require.Equal(t, [][]any{
+		{int64(21_000_043), price, true},
+		{int64(21_000_044), nil, true},
+	}, rows)
+
+	// txs are filtered by the time of their block
+	rows = nil
+	columns, err = ExportColumns(ExportTxs, []string{"txhash", "blocktime", "trace_address"})
+	require.NoError(t, err)
+	require.NoError(t, db.ExportRows(t.Context(), ExportTxs, columns, &StatsWindow{ToTime: 1_730_000_000}, collect))
+	require.Equal(t, [][]any{
+		{"0x" + strings.Repeat("1", 63) + "a", int64(1_730_000_000), "1.0"},
+	}, rows)
+}
@@ -0,0 +1,204 @@
+package database
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database/vars"
+)
+
+// ValidateLabel checks the address and name of a label, and normalizes it
+func ValidateLabel(label *Label) error {
+	if label == nil {
+		return fmt.Errorf("%w: label must be set", ErrInvalidInput)
+	}
+	address, err := ParseAddress(string(label.Address))
+	if err != nil {
+		return err
+	}
+	label.Address = address
+	label.Name = strings.TrimSpace(label.Name)
+	if label.Name == "" {
+		return fmt.Errorf("%w: label name must be set", ErrInvalidInput)
+	}
+	label.Category = strings.ToLower(strings.TrimSpace(label.Category))
+	return nil
+}
+
+// ReadLabels parses labels in the given format: "json", an array of labels,
+// or "csv", with an address,name,category header.
+func ReadLabels(r io.Reader, format string) ([]*Label, error) {
+	labels := []*Label{}
+	switch format {
+	case "json":
+		if err := json.NewDecoder(r).Decode(&labels); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+		}
+	case "csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+		}
+		for i, record := range records {
+			if i == 0 && strings.EqualFold(record[0], "address") {
+				continue
+			}
+			if len(record) < 2 || len(record) > 3 {
+				return nil, fmt.Errorf("%w: line %d must be address,name[,category]", ErrInvalidInput, i+1)
+			}
+			label := &Label{Address: common.Address(record[0]), Name: record[1]}
+			if len(record) == 3 {
+				label.Category = record[2]
+			}
+			labels = append(labels, label)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown labels format %q", ErrInvalidInput, format)
+	}
+	for i, label := range labels {
+		if err := ValidateLabel(label); err != nil {
+			return nil, fmt.Errorf("label %d: %w", i+1, err)
+		}
+	}
+	return labels, nil
+}
+
+// LoadLabelsFile reads the labels of a .csv or .json file
+func LoadLabelsFile(path string) ([]*Label, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadLabels(f, strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."))
+}
+
+// SetLabel creates or replaces the label of an address.
+// Returns ErrInvalidInput if the label is invalid.
+func (s *DatabaseService) SetLabel(ctx context.Context, label *Label) (err error) {
+	ctx, span := startSpan(ctx, "SetLabel")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if err := ValidateLabel(label); err != nil {
+		return err
+	}
+	_, err = s.DB.ExecContext(ctx, upsertLabel(), label.Address, label.Name, label.Category)
+	return wrapError(err)
+}
+
+// upsertLabel is the query creating or replacing a label
+func upsertLabel() string {
+	return `INSERT INTO ` + vars.TableLabels + ` (address, name, category) VALUES ($1, $2, $3)
+		ON CONFLICT (address) DO UPDATE SET name = EXCLUDED.name, category = EXCLUDED.category, updated_at = now()`
+}
+
+// ImportLabels creates or replaces labels in a single transaction
+func (s *DatabaseService) ImportLabels(ctx context.Context, labels []*Label) (err error) {
+	ctx, span := startSpan(ctx, "ImportLabels")
+	defer func() { endSpan(span, err) }()
+
+	for _, label := range labels {
+		if err := ValidateLabel(label); err != nil {
+			return err
+		}
+	}
+	tx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, label := range labels {
+		if _, err := tx.ExecContext(ctx, upsertLabel(), label.Address, label.Name, label.Category); err != nil {
+			return wrapError(err)
+		}
+	}
+	return wrapError(tx.Commit())
+}
+
+// DeleteLabel removes the label of an address.
+// Returns ErrNotFound if the address has no label.
+func (s *DatabaseService) DeleteLabel(ctx context.Context, address string) (err error) {
+	ctx, span := startSpan(ctx, "DeleteLabel")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	normalized, err := ParseAddress(address)
+	if err != nil {
+		return err
+	}
+	res, err := s.DB.ExecContext(ctx, `DELETE FROM `+vars.TableLabels+` WHERE address = ($1)`, normalized)
+	if err != nil {
+		return wrapError(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return wrapError(err)
+	} else if n == 0 {
+		return fmt.Errorf("%w: no label for %s", ErrNotFound, address)
+	}
+	return nil
+}
+
+// GetLabels returns all labels, ordered by address
+func (s *DatabaseService) GetLabels(ctx context.Context) (labels []*Label, err error) {
+	ctx, span := startSpan(ctx, "GetLabels")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	labels = []*Label{}
+	err = s.reader().SelectContext(ctx, &labels, `SELECT address, name, category FROM `+vars.TableLabels+` ORDER BY address`)
+	return labels, wrapError(err)
+}
+
+// labelsOf returns the labels of the given addresses
+func (s *DatabaseService) labelsOf(ctx context.Context, addresses []common.Address) (map[common.Address]*Label, error) {
+	labels := map[common.Address]*Label{}
+	if len(addresses) == 0 {
+		return labels, nil
+	}
+	rows := []*Label{}
+	if err := s.reader().SelectContext(ctx, &rows, `SELECT address, name, category FROM `+vars.TableLabels+`
+		WHERE address = ANY($1)`, hexArray[common.Address](addresses)); err != nil {
+		return nil, wrapError(err)
+	}
+	for _, label := range rows {
+		labels[label.Address] = label
+	}
+	return labels, nil
+}
+
+// attachLabels sets the labels of the miner of block, if not nil, and of the senders and recipients of txs
+func (s *DatabaseService) attachLabels(ctx context.Context, block *MEVBlock, txs []*MEVTransaction) error {
+	addresses := []common.Address{}
+	if block != nil {
+		addresses = append(addresses, block.Miner)
+	}
+	for _, tx := range txs {
+		addresses = append(addresses, tx.From, tx.To)
+	}
+	labels, err := s.labelsOf(ctx, addresses)
+	if err != nil {
+		return err
+	}
+	if block != nil {
+		block.MinerLabel = labels[block.Miner]
+	}
+	for _, tx := range txs {
+		tx.FromLabel = labels[tx.From]
+		tx.ToLabel = labels[tx.To]
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBuilder = "0x95222290dd7278aa3ddd389cc1e1d165cc4bafe5"
+	testTxHash  = "0x197431ccae307bf133272e57305aa366b8314b8f89f41f6e6c129e3131677ad1"
+)
+
+func TestReadLabels(t *testing.T) {
+	expected := []*Label{
+		{Address: testBuilder, Name: "beaverbuild", Category: "builder"},
+		{Address: "0xae2fc483527b8ef99eb5d9b44875f005ba1fae13", Name: "jaredfromsubway"},
+	}
+
+	labels, err := ReadLabels(strings.NewReader(`address,name,category
+0x95222290DD7278AA3DDD389CC1E1D165CC4BAFE5, beaverbuild, Builder
+0xae2fc483527b8ef99eb5d9b44875f005ba1fae13,jaredfromsubway
+`), "csv")
+	require.NoError(t, err)
+	require.Equal(t, expected, labels)
+
+	labels, err = ReadLabels(strings.NewReader(`[
+		{"address": "0x95222290DD7278AA3DDD389CC1E1D165CC4BAFE5", "name": "beaverbuild", "category": "builder"},
+		{"address": "0xae2fc483527b8ef99eb5d9b44875f005ba1fae13", "name": "jaredfromsubway"}
+	]`), "json")
+	require.NoError(t, err)
+	require.Equal(t, expected, labels)
+
+	for format, content := range map[string]string{
+		"csv":  "0x1234,foo\n",
+		"json": `[{"address": "` + testBuilder + `", "name": " "}]`,
+		"xml":  "",
+	} {
+		_, err := ReadLabels(strings.NewReader(content), format)
+		require.ErrorIs(t, err, ErrInvalidInput, format)
+	}
+	_, err = ReadLabels(strings.NewReader(testBuilder+",a,b,c\n"), "csv")
+	require.ErrorIs(t, err, ErrInvalidInput)
+}
+
+// Test_Labels() tests editing labels, and that they are joined into blocks and txs
+func Test_Labels(t *testing.T) {
+	db := resetDatabase(t)
+	mevBlock := createMEVBlock()
+	mevBlock.Miner = testBuilder
+	mevTx := createMEVTx(testTxHash)
+	mevTx.To = testBuilder
+	mevBlock.MEVTransactions = []*MEVTransaction{mevTx}
+	require.NoError(t, db.SaveMEVBLock(t.Context(), mevBlock, mevBlock.MEVTransactions))
+
+	require.NoError(t, db.ImportLabels(t.Context(), []*Label{
+		{Address: testBuilder, Name: "builder0x69", Category: "builder"},
+		{Address: "0xae2fc483527b8ef99eb5d9b44875f005ba1fae13", Name: "jaredfromsubway", Category: "searcher"},
+	}))
+	// replaces the imported label
+	builder := &Label{Address: common.Address(strings.ToUpper(testBuilder[2:])), Name: "beaverbuild", Category: "builder"}
+	require.ErrorIs(t, db.SetLabel(t.Context(), builder), ErrInvalidInput)
+	builder.Address = "0x" + builder.Address
+	require.NoError(t, db.SetLabel(t.Context(), builder))
+	require.Equal(t, common.Address(testBuilder), builder.Address)
+
+	labels, err := db.GetLabels(t.Context())
+	require.NoError(t, err)
+	require.Len(t, labels, 2)
+	require.Equal(t, builder, labels[0])
+
+	control, err := db.GetMEVBlock(t.Context(), string(mevBlock.BlockHash))
+	require.NoError(t, err)
+	require.Equal(t, builder, control.MinerLabel)
+	require.Equal(t, builder, control.MEVTransactions[0].ToLabel)
+	require.Nil(t, control.MEVTransactions[0].FromLabel)
+
+	tx, err := db.GetMEVTx(t.Context(), testTxHash)
+	require.NoError(t, err)
+	require.Equal(t, builder, tx.ToLabel)
+
+	require.NoError(t, db.DeleteLabel(t.Context(), testBuilder))
+	require.ErrorIs(t, db.DeleteLabel(t.Context(), testBuilder), ErrNotFound)
+	tx, err = db.GetMEVTx(t.Context(), testTxHash)
+	require.NoError(t, err)
+	require.Nil(t, tx.ToLabel)
+}
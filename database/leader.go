@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+	"log/slog"
+	"time"
+
+	"github.com/holisticode/mev-rpc/database/vars"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// DefaultLeaderRetryInterval is how often a standby tries to become leader
+	DefaultLeaderRetryInterval = 5 * time.Second
+	// DefaultLeaderCheckInterval is how often the leader verifies it still holds the lock
+	DefaultLeaderCheckInterval = 2 * time.Second
+)
+
+// LeaderElector elects a single leader among the instances sharing the same database,
+// using a session level Postgres advisory lock.
+// The lock is released by Postgres as soon as the leader's connection is closed,
+// e.g. because the leader died, which allows a standby to take over.
+type LeaderElector struct {
+	db  *sqlx.DB
+	log *slog.Logger
+	// lockKey identifies the advisory lock; it is derived from the table names,
+	// so that deployments using different tables don't compete with each other
+	lockKey int64
+
+	RetryInterval time.Duration
+	CheckInterval time.Duration
+}
+
+// NewLeaderElector creates an elector campaigning on db
+func NewLeaderElector(db *sqlx.DB, log *slog.Logger) *LeaderElector {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(vars.TableMEVBlocks))
+	return &LeaderElector{
+		db:            db,
+		log:           log,
+		lockKey:       int64(h.Sum64()), //nolint:gosec
+		RetryInterval: DefaultLeaderRetryInterval,
+		CheckInterval: DefaultLeaderCheckInterval,
+	}
+}
+
+// Run campaigns for leadership until ctx is done.
+// Whenever this instance becomes leader, lead is called with a context
+// which is cancelled once leadership is lost; lead must return when it is.
+func (e *LeaderElector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	for {
+		e.campaign(ctx, lead)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.RetryInterval):
+		}
+	}
+}
+
+// campaign tries to acquire the lock once, and leads while holding it
+func (e *LeaderElector) campaign(ctx context.Context, lead func(ctx context.Context)) {
+	// session level locks belong to a connection, so we need a dedicated one
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		e.log.Error("Leader election: failed to get a DB connection", "err", err)
+		return
+	}
+	defer conn.Close()
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, e.lockKey).Scan(&acquired); err != nil {
+		e.log.Error("Leader election: failed to try the lock", "err", err)
+		return
+	}
+	if !acquired {
+		e.log.Debug("Leader election: another instance is leader, standing by")
+		return
+	}
+
+	e.log.Info("Leader election: elected leader")
+	e.hold(ctx, conn, lead)
+
+	// if the connection is broken, the lock is gone anyway
+	unlockCtx, cancel := context.WithTimeout(context.Background(), e.CheckInterval)
+	defer cancel()
+	if _, err := conn.ExecContext(unlockCtx, `SELECT pg_advisory_unlock($1)`, e.lockKey); err != nil {
+		e.log.Warn("Leader election: failed to release the lock", "err", err)
+	}
+	e.log.Info("Leader election: stepped down")
+}
+
+// hold runs lead while checking that the connection holding the lock is still alive
+func (e *LeaderElector) hold(ctx context.Context, conn *sql.Conn, lead func(ctx context.Context)) {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lead(leaderCtx)
+	}()
+
+	ticker := time.NewTicker(e.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			checkCtx, cancelCheck := context.WithTimeout(ctx, e.CheckInterval)
+			err := conn.PingContext(checkCtx)
+			cancelCheck()
+			if err != nil && ctx.Err() == nil {
+				e.log.Error("Leader election: lost the connection holding the lock, stepping down", "err", err)
+				cancel()
+				<-done
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_LeaderElector() tests that only one of two electors leads, and the other takes over
+func Test_LeaderElector(t *testing.T) {
+	db := resetDatabase(t)
+	other, err := Connect(testDBDSN, nil)
+	require.NoError(t, err)
+	defer other.Close()
+
+	newElector := func(db *DatabaseService) *LeaderElector {
+		e := NewLeaderElector(db.DB, getTestLogger())
+		e.RetryInterval = 10 * time.Millisecond
+		e.CheckInterval = 10 * time.Millisecond
+		return e
+	}
+	first := newElector(db)
+	second := newElector(&DatabaseService{DB: other})
+
+	// the first elector leads until its context is cancelled
+	firstCtx, stepDown := context.WithCancel(t.Context())
+	firstLeading := make(chan struct{})
+	go first.Run(firstCtx, func(ctx context.Context) {
+		close(firstLeading)
+		<-ctx.Done()
+	})
+	<-firstLeading
+
+	secondLeading := make(chan struct{})
+	go second.Run(t.Context(), func(ctx context.Context) {
+		close(secondLeading)
+		<-ctx.Done()
+	})
+
+	// the second one stands by...
+	select {
+	case <-secondLeading:
+		t.Fatal("two leaders at the same time")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// ...until the first one steps down
+	stepDown()
+	select {
+	case <-secondLeading:
+	case <-time.After(5 * time.Second):
+		t.Fatal("standby didn't take over")
+	}
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/holisticode/mev-rpc/database/vars"
+	"github.com/jmoiron/sqlx"
+)
+
+// liquidationColumns are the columns scanned by scanLiquidation
+const liquidationColumns = `blocknumber, txhash, position, log_index, protocol, liquidator, borrower,
+	collateral_asset, seized_collateral, debt_asset, repaid_debt, bribe`
+
+// saveLiquidations inserts the liquidations of a block as part of the transaction saving it
+func saveLiquidations(ctx context.Context, tx *sqlx.Tx, blockID uint64, liquidations []*Liquidation) error {
+	insert := `INSERT INTO ` + vars.TableLiquidations + ` (block_id, ` + liquidationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+	for _, liq := range liquidations {
+		var bribe sql.NullString
+		if liq.Bribe != nil {
+			bribe = sql.NullString{String: liq.Bribe.String(), Valid: true}
+		}
+		if _, err := tx.ExecContext(ctx, insert, blockID, liq.BlockNumber, liq.TxHash, liq.Position, liq.LogIndex,
+			liq.Protocol, liq.Liquidator, liq.Borrower, liq.CollateralAsset, liq.SeizedCollateral.String(),
+			liq.DebtAsset, liq.RepaidDebt.String(), bribe); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanLiquidation scans the liquidationColumns of a row
+func scanLiquidation(row interface{ Scan(dest ...any) error }) (*Liquidation, error) {
+	var (
+		liq          Liquidation
+		seized, debt string
+		bribe        sql.NullString
+	)
+	if err := row.Scan(&liq.BlockNumber, &liq.TxHash, &liq.Position, &liq.LogIndex, &liq.Protocol, &liq.Liquidator,
+		&liq.Borrower, &liq.CollateralAsset, &seized, &liq.DebtAsset, &debt, &bribe); err != nil {
+		return nil, err
+	}
+	liq.SeizedCollateral = parseNumeric(seized)
+	liq.RepaidDebt = parseNumeric(debt)
+	if bribe.Valid {
+		liq.Bribe = parseNumeric(bribe.String)
+	}
+	return &liq, nil
+}
+
+// queryLiquidations runs a query selecting the liquidationColumns
+func (s *DatabaseService) queryLiquidations(ctx context.Context, query string, args ...any) ([]*Liquidation, error) {
+	rows, err := s.reader().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+	liquidations := []*Liquidation{}
+	for rows.Next() {
+		liq, err := scanLiquidation(rows)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		liquidations = append(liquidations, liq)
+	}
+	return liquidations, wrapError(rows.Err())
+}
+
+// GetLiquidations returns the detected liquidations matching filter, the latest first.
+// Returns ErrInvalidInput if the filter is invalid.
+func (s *DatabaseService) GetLiquidations(ctx context.Context, filter *LiquidationFilter) (liquidations []*Liquidation, err error) {
+	ctx, span := startSpan(ctx, "GetLiquidations")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if filter == nil {
+		filter = &LiquidationFilter{}
+	}
+	limit, err := queryLimit(filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	conds := []string{}
+	args := []any{}
+	addCond := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.FromBlock > 0 {
+		addCond("blocknumber >= $%d", filter.FromBlock)
+	}
+	if filter.ToBlock > 0 {
+		addCond("blocknumber <= $%d", filter.ToBlock)
+	}
+	if filter.Protocol != "" {
+		addCond("protocol = $%d", strings.ToLower(filter.Protocol))
+	}
+	if filter.Liquidator != "" {
+		address, err := ParseAddress(string(filter.Liquidator))
+		if err != nil {
+			return nil, err
+		}
+		addCond("liquidator = $%d", address)
+	}
+	if filter.Borrower != "" {
+		address, err := ParseAddress(string(filter.Borrower))
+		if err != nil {
+			return nil, err
+		}
+		addCond("borrower = $%d", address)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, limit)
+	sel := `SELECT ` + liquidationColumns + ` FROM ` + vars.TableLiquidations + where +
+		fmt.Sprintf(` ORDER BY blocknumber DESC, id LIMIT $%d`, len(args))
+	return s.queryLiquidations(ctx, sel, args...)
+}
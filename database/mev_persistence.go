@@ -1,17 +1,46 @@
 package database
 
-import "log/slog"
+import (
+	"context"
+	"log/slog"
+	"time"
+)
 
-// MEVTraceStorage groups functions we need for this work test
+// MEVTraceStorage groups functions we need for this work test.
+// All methods abort when ctx is done.
 type MEVTraceStorage interface {
-	LatestBlock() (uint64, error)
-	GetMEVTx(tx string) (*MEVTransaction, error)
-	GetMEVBlock(block string) (*MEVBlock, error)
-	OldestBlock() uint64
-	SaveMEVBLock(block *MEVBlock, txs []*MEVTransaction) error
+	LatestBlock(ctx context.Context) (uint64, error)
+	LatestBlockTime(ctx context.Context) (uint64, error)
+	GetMEVTx(ctx context.Context, tx string) (*MEVTransaction, error)
+	GetMEVBlock(ctx context.Context, block string) (*MEVBlock, error)
+	OldestBlock(ctx context.Context) uint64
+	SaveMEVBLock(ctx context.Context, block *MEVBlock, txs []*MEVTransaction) error
+	SaveUSDValues(ctx context.Context, block *MEVBlock) error
+	GetMinerStats(ctx context.Context, window *StatsWindow) ([]*MinerStats, error)
+	GetSandwiches(ctx context.Context, filter *SandwichFilter) ([]*Sandwich, error)
+	GetLiquidations(ctx context.Context, filter *LiquidationFilter) ([]*Liquidation, error)
+	GetBundle(ctx context.Context, txhash string) (*Bundle, error)
+	GetLabels(ctx context.Context) ([]*Label, error)
+	SetLabel(ctx context.Context, label *Label) error
+	DeleteLabel(ctx context.Context, address string) error
+	Ping(ctx context.Context) error
+}
+
+// BackfillQueue distributes ranges of blocks to backfill among workers.
+// Shards are leased: a shard whose lease expires is handed out again.
+type BackfillQueue interface {
+	CreateBackfillShards(ctx context.Context, from, to, shardSize uint64) (int, error)
+	ClaimBackfillShard(ctx context.Context, from, to uint64, worker string, lease time.Duration) (*BackfillShard, error)
+	RenewBackfillLease(ctx context.Context, id uint64, worker string, lease time.Duration) error
+	CompleteBackfillShard(ctx context.Context, id uint64, worker string, failedBlocks uint64) error
+}
+
+// RowExporter streams stored rows, e.g. to export them to files
+type RowExporter interface {
+	ExportRows(ctx context.Context, table ExportTable, columns []ExportColumn, window *StatsWindow, fn func(row []any) error) error
 }
 
 // NewStorage returns the service to store the data
-func NewStorage(conn string, log *slog.Logger) (*DatabaseService, error) {
-	return NewDatabaseService(conn, log)
+func NewStorage(conn string, log *slog.Logger, opts *Opts) (*DatabaseService, error) {
+	return NewDatabaseService(conn, log, opts)
 }
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/holisticode/mev-rpc/database/migrations"
+	"github.com/holisticode/mev-rpc/database/vars"
+	"github.com/jmoiron/sqlx"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// MigrationStatus describes a migration and whether it has been applied
+type MigrationStatus struct {
+	ID string
+	// AppliedAt is nil if the migration has not been applied yet
+	AppliedAt *time.Time
+}
+
+// migrationSet tracks applied migrations in the (configurable) migrations table
+func migrationSet() migrate.MigrationSet {
+	return migrate.MigrationSet{TableName: vars.TableMigrations} //nolint:exhaustruct
+}
+
+// PoolOpts configures a connection pool; zero values mean no limit
+type PoolOpts struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// DefaultPoolOpts returns the pool settings used if none are given
+func DefaultPoolOpts() *PoolOpts {
+	return &PoolOpts{
+		MaxOpenConns: DefaultMaxOpenConns,
+		MaxIdleConns: DefaultMaxIdleConns,
+	}
+}
+
+// Connect opens a connection pool to the DB, without applying migrations.
+// If pool is nil, DefaultPoolOpts are used.
+func Connect(dsn string, pool *PoolOpts) (*sqlx.DB, error) {
+	if pool == nil {
+		pool = DefaultPoolOpts()
+	}
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+	return db, nil
+}
+
+// MigrateUp applies up to maxSteps pending migrations (all if maxSteps is 0),
+// and returns the number of migrations applied
+func MigrateUp(ctx context.Context, db *sqlx.DB, maxSteps int) (int, error) {
+	return migrationSet().ExecMaxContext(ctx, db.DB, "postgres", migrations.Migrations(), migrate.Up, maxSteps)
+}
+
+// MigrateDown reverts up to maxSteps applied migrations (all if maxSteps is 0),
+// latest first, and returns the number of migrations reverted
+func MigrateDown(ctx context.Context, db *sqlx.DB, maxSteps int) (int, error) {
+	return migrationSet().ExecMaxContext(ctx, db.DB, "postgres", migrations.Migrations(), migrate.Down, maxSteps)
+}
+
+// GetMigrationStatus lists all known migrations, in order, with the time they were applied
+func GetMigrationStatus(db *sqlx.DB) ([]*MigrationStatus, error) {
+	records, err := migrationSet().GetMigrationRecords(db.DB, "postgres")
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[string]time.Time, len(records))
+	for _, r := range records {
+		applied[r.Id] = r.AppliedAt
+	}
+
+	all := migrations.Migrations().Migrations
+	status := make([]*MigrationStatus, 0, len(all))
+	for _, m := range all {
+		st := &MigrationStatus{ID: m.Id}
+		if at, ok := applied[m.Id]; ok {
+			st.AppliedAt = &at
+		}
+		status = append(status, st)
+	}
+	return status, nil
+}
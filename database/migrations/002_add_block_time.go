@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"github.com/holisticode/mev-rpc/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration002AddBlockTime stores the block timestamp, so that
+// aggregations can be restricted to a time window
+func Migration002AddBlockTime() *migrate.Migration {
+	return &migrate.Migration{
+		Id: "002-add-block-time",
+		Up: []string{`
+			ALTER TABLE ` + vars.TableMEVBlocks + ` ADD COLUMN IF NOT EXISTS blocktime bigint NOT NULL DEFAULT 0;
+			CREATE INDEX IF NOT EXISTS ` + vars.TableMEVBlocks + `_blocknumber_idx ON ` + vars.TableMEVBlocks + ` (blocknumber);
+			CREATE INDEX IF NOT EXISTS ` + vars.TableMEVBlocks + `_blocktime_idx ON ` + vars.TableMEVBlocks + ` (blocktime);
+			CREATE INDEX IF NOT EXISTS ` + vars.TableMEVTxs + `_block_id_idx ON ` + vars.TableMEVTxs + ` (block_id);
+		`},
+		Down: []string{`
+			DROP INDEX IF EXISTS ` + vars.TableMEVTxs + `_block_id_idx;
+			DROP INDEX IF EXISTS ` + vars.TableMEVBlocks + `_blocktime_idx;
+			DROP INDEX IF EXISTS ` + vars.TableMEVBlocks + `_blocknumber_idx;
+			ALTER TABLE ` + vars.TableMEVBlocks + ` DROP COLUMN IF EXISTS blocktime;
+		`},
+		DisableTransactionUp:   false,
+		DisableTransactionDown: false,
+	}
+}
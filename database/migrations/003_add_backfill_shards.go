@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"github.com/holisticode/mev-rpc/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration003AddBackfillShards adds the work table through which
+// backfill workers claim ranges of blocks
+func Migration003AddBackfillShards() *migrate.Migration {
+	return &migrate.Migration{
+		Id: "003-add-backfill-shards",
+		Up: []string{`
+			CREATE TABLE IF NOT EXISTS ` + vars.TableBackfillShards + ` (
+				id SERIAL PRIMARY KEY,
+				from_block bigint NOT NULL,
+				to_block bigint NOT NULL,
+				status text NOT NULL DEFAULT 'pending',
+				worker text NOT NULL DEFAULT '',
+				leased_until timestamptz,
+				attempts int NOT NULL DEFAULT 0,
+				failed_blocks bigint NOT NULL DEFAULT 0,
+				UNIQUE (from_block, to_block)
+			);
+			CREATE INDEX IF NOT EXISTS ` + vars.TableBackfillShards + `_status_idx ON ` + vars.TableBackfillShards + ` (status, from_block);
+		`},
+		Down: []string{`
+			DROP TABLE IF EXISTS ` + vars.TableBackfillShards + `;
+		`},
+		DisableTransactionUp:   false,
+		DisableTransactionDown: false,
+	}
+}
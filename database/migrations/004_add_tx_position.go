@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"github.com/holisticode/mev-rpc/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration004AddTxPosition stores where in the block a transfer happened,
+// and the gas used by it. Rows stored before default to position 0.
+func Migration004AddTxPosition() *migrate.Migration {
+	return &migrate.Migration{
+		Id: "004-add-tx-position",
+		Up: []string{`
+			ALTER TABLE ` + vars.TableMEVTxs + ` ADD COLUMN IF NOT EXISTS position bigint NOT NULL DEFAULT 0;
+			ALTER TABLE ` + vars.TableMEVTxs + ` ADD COLUMN IF NOT EXISTS trace_address bigint[] NOT NULL DEFAULT '{}';
+			ALTER TABLE ` + vars.TableMEVTxs + ` ADD COLUMN IF NOT EXISTS gas_used bigint NOT NULL DEFAULT 0;
+		`},
+		Down: []string{`
+			ALTER TABLE ` + vars.TableMEVTxs + ` DROP COLUMN IF EXISTS gas_used;
+			ALTER TABLE ` + vars.TableMEVTxs + ` DROP COLUMN IF EXISTS trace_address;
+			ALTER TABLE ` + vars.TableMEVTxs + ` DROP COLUMN IF EXISTS position;
+		`},
+		DisableTransactionUp:   false,
+		DisableTransactionDown: false,
+	}
+}
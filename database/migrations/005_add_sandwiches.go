@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"github.com/holisticode/mev-rpc/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration005AddSandwiches stores the sandwich attacks detected in a block
+func Migration005AddSandwiches() *migrate.Migration {
+	return &migrate.Migration{
+		Id: "005-add-sandwiches",
+		Up: []string{`
+			CREATE TABLE IF NOT EXISTS ` + vars.TableSandwiches + ` (
+				id SERIAL PRIMARY KEY,
+				block_id int NOT NULL,
+				blocknumber bigint NOT NULL,
+				attacker text NOT NULL,
+				pool text NOT NULL,
+				protocol text NOT NULL,
+				front_tx text NOT NULL,
+				back_tx text NOT NULL,
+				victim_txs text[] NOT NULL,
+				victims text[] NOT NULL,
+				profit_token text NOT NULL,
+				profit text NOT NULL,
+				CONSTRAINT fk_block FOREIGN KEY(block_id) REFERENCES ` + vars.TableMEVBlocks + `(id)
+			);
+			CREATE INDEX IF NOT EXISTS ` + vars.TableSandwiches + `_block_id_idx ON ` + vars.TableSandwiches + ` (block_id);
+			CREATE INDEX IF NOT EXISTS ` + vars.TableSandwiches + `_blocknumber_idx ON ` + vars.TableSandwiches + ` (blocknumber);
+			CREATE INDEX IF NOT EXISTS ` + vars.TableSandwiches + `_attacker_idx ON ` + vars.TableSandwiches + ` (attacker);
+		`},
+		Down: []string{`
+			DROP TABLE IF EXISTS ` + vars.TableSandwiches + `;
+		`},
+		DisableTransactionUp:   false,
+		DisableTransactionDown: false,
+	}
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"github.com/holisticode/mev-rpc/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration006AddArbitrages stores the cyclic arbitrages of the txs paying the coinbase
+func Migration006AddArbitrages() *migrate.Migration {
+	return &migrate.Migration{
+		Id: "006-add-arbitrages",
+		Up: []string{`
+			CREATE TABLE IF NOT EXISTS ` + vars.TableArbitrages + ` (
+				id SERIAL PRIMARY KEY,
+				block_id int NOT NULL,
+				blocknumber bigint NOT NULL,
+				txhash text NOT NULL,
+				path text[] NOT NULL,
+				pools text[] NOT NULL,
+				profit_token text NOT NULL,
+				profit text NOT NULL,
+				bribe text NOT NULL,
+				bribe_ratio double precision,
+				CONSTRAINT fk_block FOREIGN KEY(block_id) REFERENCES ` + vars.TableMEVBlocks + `(id),
+				UNIQUE (block_id, txhash)
+			);
+			CREATE INDEX IF NOT EXISTS ` + vars.TableArbitrages + `_txhash_idx ON ` + vars.TableArbitrages + ` (txhash);
+		`},
+		Down: []string{`
+			DROP TABLE IF EXISTS ` + vars.TableArbitrages + `;
+		`},
+		DisableTransactionUp:   false,
+		DisableTransactionDown: false,
+	}
+}
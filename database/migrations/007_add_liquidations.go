@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"github.com/holisticode/mev-rpc/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration007AddLiquidations stores the liquidations on lending protocols detected in a block
+func Migration007AddLiquidations() *migrate.Migration {
+	return &migrate.Migration{
+		Id: "007-add-liquidations",
+		Up: []string{`
+			CREATE TABLE IF NOT EXISTS ` + vars.TableLiquidations + ` (
+				id SERIAL PRIMARY KEY,
+				block_id int NOT NULL,
+				blocknumber bigint NOT NULL,
+				txhash text NOT NULL,
+				position bigint NOT NULL,
+				log_index bigint NOT NULL,
+				protocol text NOT NULL,
+				liquidator text NOT NULL,
+				borrower text NOT NULL,
+				collateral_asset text NOT NULL,
+				seized_collateral text NOT NULL,
+				debt_asset text NOT NULL,
+				repaid_debt text NOT NULL,
+				bribe text,
+				CONSTRAINT fk_block FOREIGN KEY(block_id) REFERENCES ` + vars.TableMEVBlocks + `(id)
+			);
+			CREATE INDEX IF NOT EXISTS ` + vars.TableLiquidations + `_block_id_idx ON ` + vars.TableLiquidations + ` (block_id);
+			CREATE INDEX IF NOT EXISTS ` + vars.TableLiquidations + `_blocknumber_idx ON ` + vars.TableLiquidations + ` (blocknumber);
+			CREATE INDEX IF NOT EXISTS ` + vars.TableLiquidations + `_liquidator_idx ON ` + vars.TableLiquidations + ` (liquidator);
+			CREATE INDEX IF NOT EXISTS ` + vars.TableLiquidations + `_borrower_idx ON ` + vars.TableLiquidations + ` (borrower);
+		`},
+		Down: []string{`
+			DROP TABLE IF EXISTS ` + vars.TableLiquidations + `;
+		`},
+		DisableTransactionUp:   false,
+		DisableTransactionDown: false,
+	}
+}
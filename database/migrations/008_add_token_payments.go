@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"github.com/holisticode/mev-rpc/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration008AddTokenPayments stores the ERC-20 transfers to the miner of a block
+func Migration008AddTokenPayments() *migrate.Migration {
+	return &migrate.Migration{
+		Id: "008-add-token-payments",
+		Up: []string{`
+			CREATE TABLE IF NOT EXISTS ` + vars.TableTokenPayments + ` (
+				id SERIAL PRIMARY KEY,
+				block_id int NOT NULL,
+				blocknumber bigint NOT NULL,
+				txhash text NOT NULL,
+				position bigint NOT NULL,
+				log_index bigint NOT NULL,
+				src text NOT NULL,
+				token text NOT NULL,
+				decimals smallint,
+				amount text NOT NULL,
+				CONSTRAINT fk_block FOREIGN KEY(block_id) REFERENCES ` + vars.TableMEVBlocks + `(id)
+			);
+			CREATE INDEX IF NOT EXISTS ` + vars.TableTokenPayments + `_block_id_idx ON ` + vars.TableTokenPayments + ` (block_id);
+			CREATE INDEX IF NOT EXISTS ` + vars.TableTokenPayments + `_token_idx ON ` + vars.TableTokenPayments + ` (token);
+		`},
+		Down: []string{`
+			DROP TABLE IF EXISTS ` + vars.TableTokenPayments + `;
+		`},
+		DisableTransactionUp:   false,
+		DisableTransactionDown: false,
+	}
+}
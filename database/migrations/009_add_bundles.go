@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/holisticode/mev-rpc/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration009AddBundles stores the bundles reconstructed in a block
+func Migration009AddBundles() *migrate.Migration {
+	return &migrate.Migration{
+		Id: "009-add-bundles",
+		Up: []string{`
+			CREATE TABLE IF NOT EXISTS ` + vars.TableBundles + ` (
+				id SERIAL PRIMARY KEY,
+				block_id int NOT NULL,
+				blocknumber bigint NOT NULL,
+				position bigint NOT NULL,
+				txhashes text[] NOT NULL,
+				payment text NOT NULL,
+				CONSTRAINT fk_block FOREIGN KEY(block_id) REFERENCES ` + vars.TableMEVBlocks + `(id)
+			);
+			CREATE INDEX IF NOT EXISTS ` + vars.TableBundles + `_block_id_idx ON ` + vars.TableBundles + ` (block_id);
+			CREATE INDEX IF NOT EXISTS ` + vars.TableBundles + `_txhashes_idx ON ` + vars.TableBundles + ` USING GIN (txhashes);
+		`},
+		Down: []string{`
+			DROP TABLE IF EXISTS ` + vars.TableBundles + `;
+		`},
+		DisableTransactionUp:   false,
+		DisableTransactionDown: false,
+	}
+}
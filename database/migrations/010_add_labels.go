@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"github.com/holisticode/mev-rpc/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration010AddLabels stores human readable labels of addresses
+func Migration010AddLabels() *migrate.Migration {
+	return &migrate.Migration{
+		Id: "010-add-labels",
+		Up: []string{`
+			CREATE TABLE IF NOT EXISTS ` + vars.TableLabels + ` (
+				address text PRIMARY KEY,
+				name text NOT NULL,
+				category text NOT NULL DEFAULT '',
+				updated_at timestamptz NOT NULL DEFAULT now()
+			);
+		`},
+		Down: []string{`
+			DROP TABLE IF EXISTS ` + vars.TableLabels + `;
+		`},
+		DisableTransactionUp:   false,
+		DisableTransactionDown: false,
+	}
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"github.com/holisticode/mev-rpc/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration011NormalizeHex lowercases the hashes and addresses stored as the node returned them
+// (or, for labels, as they were imported), now that they are normalized at ingestion and lookups
+// use lowercase values
+func Migration011NormalizeHex() *migrate.Migration {
+	return &migrate.Migration{
+		Id: "011-normalize-hex",
+		Up: []string{`
+			UPDATE ` + vars.TableMEVBlocks + ` SET blockhash = lower(blockhash), miner = lower(miner)
+				WHERE blockhash <> lower(blockhash) OR miner <> lower(miner);
+			UPDATE ` + vars.TableMEVTxs + ` SET txhash = lower(txhash), src = lower(src), dest = lower(dest)
+				WHERE txhash <> lower(txhash) OR src <> lower(src) OR dest <> lower(dest);
+			UPDATE ` + vars.TableSandwiches + ` SET attacker = lower(attacker), pool = lower(pool),
+				front_tx = lower(front_tx), back_tx = lower(back_tx), profit_token = lower(profit_token),
+				victim_txs = lower(victim_txs::text)::text[], victims = lower(victims::text)::text[]
+				WHERE attacker <> lower(attacker) OR pool <> lower(pool) OR front_tx <> lower(front_tx) OR
+					back_tx <> lower(back_tx) OR profit_token <> lower(profit_token) OR
+					victim_txs::text <> lower(victim_txs::text) OR victims::text <> lower(victims::text);
+			UPDATE ` + vars.TableLiquidations + ` SET txhash = lower(txhash), liquidator = lower(liquidator),
+				borrower = lower(borrower), collateral_asset = lower(collateral_asset), debt_asset = lower(debt_asset)
+				WHERE txhash <> lower(txhash) OR liquidator <> lower(liquidator) OR borrower <> lower(borrower) OR
+					collateral_asset <> lower(collateral_asset) OR debt_asset <> lower(debt_asset);
+			UPDATE ` + vars.TableTokenPayments + ` SET txhash = lower(txhash), src = lower(src), token = lower(token)
+				WHERE txhash <> lower(txhash) OR src <> lower(src) OR token <> lower(token);
+			UPDATE ` + vars.TableBundles + ` SET txhashes = lower(txhashes::text)::text[]
+				WHERE txhashes::text <> lower(txhashes::text);
+			UPDATE ` + vars.TableArbitrages + ` SET txhash = lower(txhash), profit_token = lower(profit_token),
+				path = lower(path::text)::text[], pools = lower(pools::text)::text[]
+				WHERE txhash <> lower(txhash) OR profit_token <> lower(profit_token) OR
+					path::text <> lower(path::text) OR pools::text <> lower(pools::text);
+			-- a label set for both cases of an address keeps the most recent one
+			DELETE FROM ` + vars.TableLabels + ` l USING ` + vars.TableLabels + ` o
+				WHERE lower(l.address) = lower(o.address) AND l.address <> o.address
+					AND (l.updated_at, l.address) < (o.updated_at, o.address);
+			UPDATE ` + vars.TableLabels + ` SET address = lower(address) WHERE address <> lower(address);
+		`},
+		// the original case can't be restored, and lowercase values are valid for older versions too
+		Down:                   []string{},
+		DisableTransactionUp:   false,
+		DisableTransactionDown: false,
+	}
+}
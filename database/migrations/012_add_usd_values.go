@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"github.com/holisticode/mev-rpc/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration012AddUSDValues stores the ETH/USD price of a block and the USD values of its MEV.
+// Rows stored before, or without a price, are NULL.
+func Migration012AddUSDValues() *migrate.Migration {
+	return &migrate.Migration{
+		Id: "012-add-usd-values",
+		Up: []string{`
+			ALTER TABLE ` + vars.TableMEVBlocks + ` ADD COLUMN IF NOT EXISTS ethusd double precision;
+			ALTER TABLE ` + vars.TableMEVBlocks + ` ADD COLUMN IF NOT EXISTS total_usd double precision;
+			ALTER TABLE ` + vars.TableMEVTxs + ` ADD COLUMN IF NOT EXISTS value_usd double precision;
+		`},
+		Down: []string{`
+			ALTER TABLE ` + vars.TableMEVTxs + ` DROP COLUMN IF EXISTS value_usd;
+			ALTER TABLE ` + vars.TableMEVBlocks + ` DROP COLUMN IF EXISTS total_usd;
+			ALTER TABLE ` + vars.TableMEVBlocks + ` DROP COLUMN IF EXISTS ethusd;
+		`},
+		DisableTransactionUp:   false,
+		DisableTransactionDown: false,
+	}
+}
@@ -5,8 +5,23 @@ import (
 	migrate "github.com/rubenv/sql-migrate"
 )
 
-var Migrations = migrate.MemoryMigrationSource{
-	Migrations: []*migrate.Migration{
-		Migration001InitDatabase,
-	},
+// Migrations returns all migrations, in order.
+// They are built on each call, as table names can be configured at runtime.
+func Migrations() migrate.MemoryMigrationSource {
+	return migrate.MemoryMigrationSource{
+		Migrations: []*migrate.Migration{
+			Migration001InitDatabase(),
+			Migration002AddBlockTime(),
+			Migration003AddBackfillShards(),
+			Migration004AddTxPosition(),
+			Migration005AddSandwiches(),
+			Migration006AddArbitrages(),
+			Migration007AddLiquidations(),
+			Migration008AddTokenPayments(),
+			Migration009AddBundles(),
+			Migration010AddLabels(),
+			Migration011NormalizeHex(),
+			Migration012AddUSDValues(),
+		},
+	}
 }
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database/vars"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// DefaultQueryLimit is the number of results returned by list queries if no limit is given
+	DefaultQueryLimit = 100
+	// MaxQueryLimit is the maximum number of results of list queries
+	MaxQueryLimit = 1000
+)
+
+// sandwichColumns are the columns scanned by scanSandwich
+const sandwichColumns = `blocknumber, attacker, pool, protocol, front_tx, back_tx, victim_txs, victims, profit_token, profit`
+
+// saveSandwiches inserts the sandwiches of a block as part of the transaction saving it
+func saveSandwiches(ctx context.Context, tx *sqlx.Tx, blockID uint64, sandwiches []*Sandwich) error {
+	insert := `INSERT INTO ` + vars.TableSandwiches + ` (block_id, ` + sandwichColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	for _, sw := range sandwiches {
+		if _, err := tx.ExecContext(ctx, insert, blockID, sw.BlockNumber, sw.Attacker, sw.Pool, sw.Protocol,
+			sw.FrontRunTx, sw.BackRunTx, hexArray[common.Hash](sw.VictimTxs), hexArray[common.Address](sw.Victims),
+			sw.ProfitToken, sw.Profit.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanSandwich scans the sandwichColumns of a row
+func scanSandwich(row interface{ Scan(dest ...any) error }) (*Sandwich, error) {
+	var (
+		sw     Sandwich
+		profit string
+	)
+	if err := row.Scan(&sw.BlockNumber, &sw.Attacker, &sw.Pool, &sw.Protocol, &sw.FrontRunTx, &sw.BackRunTx,
+		(*hexArray[common.Hash])(&sw.VictimTxs), (*hexArray[common.Address])(&sw.Victims), &sw.ProfitToken, &profit); err != nil {
+		return nil, err
+	}
+	sw.Profit = parseNumeric(profit)
+	return &sw, nil
+}
+
+// querySandwiches runs a query selecting the sandwichColumns
+func (s *DatabaseService) querySandwiches(ctx context.Context, query string, args ...any) ([]*Sandwich, error) {
+	rows, err := s.reader().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+	sandwiches := []*Sandwich{}
+	for rows.Next() {
+		sw, err := scanSandwich(rows)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		sandwiches = append(sandwiches, sw)
+	}
+	return sandwiches, wrapError(rows.Err())
+}
+
+// GetSandwiches returns the detected sandwiches matching filter, the latest first.
+// Returns ErrInvalidInput if the filter is invalid.
+func (s *DatabaseService) GetSandwiches(ctx context.Context, filter *SandwichFilter) (sandwiches []*Sandwich, err error) {
+	ctx, span := startSpan(ctx, "GetSandwiches")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if filter == nil {
+		filter = &SandwichFilter{}
+	}
+	limit, err := queryLimit(filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	conds := []string{}
+	args := []any{}
+	addCond := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.FromBlock > 0 {
+		addCond("blocknumber >= $%d", filter.FromBlock)
+	}
+	if filter.ToBlock > 0 {
+		addCond("blocknumber <= $%d", filter.ToBlock)
+	}
+	if filter.Attacker != "" {
+		address, err := ParseAddress(string(filter.Attacker))
+		if err != nil {
+			return nil, err
+		}
+		addCond("attacker = $%d", address)
+	}
+	if filter.Pool != "" {
+		address, err := ParseAddress(string(filter.Pool))
+		if err != nil {
+			return nil, err
+		}
+		addCond("pool = $%d", address)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, limit)
+	sel := `SELECT ` + sandwichColumns + ` FROM ` + vars.TableSandwiches + where +
+		fmt.Sprintf(` ORDER BY blocknumber DESC, id LIMIT $%d`, len(args))
+	return s.querySandwiches(ctx, sel, args...)
+}
+
+// queryLimit applies the default to limit, and checks it doesn't exceed MaxQueryLimit
+func queryLimit(limit uint64) (uint64, error) {
+	switch {
+	case limit == 0:
+		return DefaultQueryLimit, nil
+	case limit > MaxQueryLimit:
+		return 0, fmt.Errorf("%w: limit must not exceed %d", ErrInvalidInput, MaxQueryLimit)
+	}
+	return limit, nil
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/holisticode/mev-rpc/database/vars"
+	"github.com/jmoiron/sqlx"
+)
+
+// tokenPaymentColumns are the columns scanned by scanTokenPayment
+const tokenPaymentColumns = `blocknumber, txhash, position, log_index, src, token, decimals, amount`
+
+// saveTokenPayments inserts the token payments of a block as part of the transaction saving it
+func saveTokenPayments(ctx context.Context, tx *sqlx.Tx, blockID uint64, payments []*TokenPayment) error {
+	insert := `INSERT INTO ` + vars.TableTokenPayments + ` (block_id, ` + tokenPaymentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	for _, p := range payments {
+		if _, err := tx.ExecContext(ctx, insert, blockID, p.BlockNumber, p.TxHash, p.Position, p.LogIndex,
+			p.From, p.Token, nullDecimals(p.Decimals), p.Amount.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanTokenPayment scans the tokenPaymentColumns of a row
+func scanTokenPayment(row interface{ Scan(dest ...any) error }) (*TokenPayment, error) {
+	var (
+		p        TokenPayment
+		decimals sql.NullInt16
+		amount   string
+	)
+	if err := row.Scan(&p.BlockNumber, &p.TxHash, &p.Position, &p.LogIndex, &p.From, &p.Token,
+		&decimals, &amount); err != nil {
+		return nil, err
+	}
+	p.Decimals = parseDecimals(decimals)
+	p.Amount = parseNumeric(amount)
+	return &p, nil
+}
+
+// getTokenPayments returns the token payments of a block, in block order
+func (s *DatabaseService) getTokenPayments(ctx context.Context, blockID uint64) ([]*TokenPayment, error) {
+	sel := `SELECT ` + tokenPaymentColumns + ` FROM ` + vars.TableTokenPayments + `
+		WHERE block_id = ($1) ORDER BY position, log_index`
+	rows, err := s.reader().QueryContext(ctx, sel, blockID)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+	var payments []*TokenPayment
+	for rows.Next() {
+		p, err := scanTokenPayment(rows)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		payments = append(payments, p)
+	}
+	return payments, wrapError(rows.Err())
+}
+
+// nullDecimals stores unknown decimals as NULL
+func nullDecimals(decimals *uint8) sql.NullInt16 {
+	if decimals == nil {
+		return sql.NullInt16{}
+	}
+	return sql.NullInt16{Int16: int16(*decimals), Valid: true}
+}
+
+// parseDecimals is the inverse of nullDecimals
+func parseDecimals(decimals sql.NullInt16) *uint8 {
+	if !decimals.Valid {
+		return nil
+	}
+	d := uint8(decimals.Int16) //nolint:gosec // decimals are stored from a uint8
+	return &d
+}
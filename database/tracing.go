@@ -0,0 +1,27 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/holisticode/mev-rpc/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startSpan starts a span for a DB operation
+//
+//nolint:ireturn
+func startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "db "+operation, trace.SpanKindClient,
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", operation))
+}
+
+// endSpan ends a DB span; missing data and invalid input are not errors of the DB
+func endSpan(span trace.Span, err error) {
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidInput) {
+		err = nil
+	}
+	tracing.End(span, err)
+}
@@ -2,27 +2,127 @@ package database
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"math/big"
 	"time"
+
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/lib/pq"
 )
 
 // MEVBlock is the datatype for a block we are going to store in the DB
 type MEVBlock struct {
 	BlockNumber     uint64            `json:"blockNumber"` //nolint:tagliatelle
-	BlockHash       string            `json:"blockHash"`   //nolint:tagliatelle
+	BlockHash       common.Hash       `json:"blockHash"`   //nolint:tagliatelle
 	MEVTransactions []*MEVTransaction `json:"transactions"`
-	Miner           string            `json:"miner"`
+	Miner           common.Address    `json:"miner"`
 	IsFlashbotMiner bool              `json:"flashbot"`
 	TotalMinerValue *big.Int          `json:"totalMinerValue"` //nolint:tagliatelle
+	Timestamp       uint64            `json:"timestamp"`
+	// ETHUSD is the ETH/USD price at Timestamp, and TotalMinerValueUSD the total at that price;
+	// both are nil if no price was available when the block was indexed
+	ETHUSD             *float64       `json:"ethUsd,omitempty"`             //nolint:tagliatelle
+	TotalMinerValueUSD *float64       `json:"totalMinerValueUsd,omitempty"` //nolint:tagliatelle
+	Sandwiches         []*Sandwich    `json:"sandwiches,omitempty"`
+	Liquidations       []*Liquidation `json:"liquidations,omitempty"`
+	// TokenPayments are ERC-20 transfers to the miner, which are not part of TotalMinerValue
+	TokenPayments []*TokenPayment `json:"tokenPayments,omitempty"` //nolint:tagliatelle
+	TokenTotals   []*TokenTotal   `json:"tokenTotals,omitempty"`   //nolint:tagliatelle
+	Bundles       []*Bundle       `json:"bundles,omitempty"`
+	MinerLabel    *Label          `json:"minerLabel,omitempty"` //nolint:tagliatelle
 }
 
 // MEVTransaction is the datatype for a tx we are going to store in the DB
 type MEVTransaction struct {
-	BlockNumber uint64   `json:"blockNumber"` //nolint:tagliatelle
-	TXHash      string   `json:"txHash"`      //nolint:tagliatelle
-	From        string   `json:"from"`
-	To          string   `json:"to"`
-	Value       *big.Int `json:"value"`
+	BlockNumber uint64         `json:"blockNumber"` //nolint:tagliatelle
+	TXHash      common.Hash    `json:"txHash"`      //nolint:tagliatelle
+	From        common.Address `json:"from"`
+	To          common.Address `json:"to"`
+	Value       *big.Int       `json:"value"`
+	// ValueUSD is Value at the ETH/USD price of the block, if any
+	ValueUSD *float64 `json:"valueUsd,omitempty"` //nolint:tagliatelle
+	// Position is the index of the tx in the block
+	Position uint64 `json:"position"`
+	// TraceAddress locates the transfer in the call tree of the tx; empty for the top level call
+	TraceAddress []uint64 `json:"traceAddress"` //nolint:tagliatelle
+	// GasUsed is the gas used by the call doing the transfer
+	GasUsed uint64 `json:"gasUsed"` //nolint:tagliatelle
+	// Arbitrage is set if the tx is a cyclic arbitrage; it is shared by all transfers of the tx
+	Arbitrage *Arbitrage `json:"arbitrage,omitempty"`
+	// FromLabel and ToLabel are set if the addresses are labeled
+	FromLabel *Label `json:"fromLabel,omitempty"` //nolint:tagliatelle
+	ToLabel   *Label `json:"toLabel,omitempty"`   //nolint:tagliatelle
+}
+
+// Arbitrage is a cyclic arbitrage within a single transaction: a path of swaps
+// starting and ending in the same token
+type Arbitrage struct {
+	// Path are the tokens traded, starting and ending with ProfitToken
+	Path []common.Address `json:"path"`
+	// Pools are the pools swapped on, one per step of the path
+	Pools []common.Address `json:"pools"`
+	// Profit is in ProfitToken, before gas and the bribe; it can be negative
+	ProfitToken common.Address `json:"profitToken"` //nolint:tagliatelle
+	Profit      *big.Int       `json:"profit"`
+	// Bribe is the sum of the coinbase transfers of the tx, in wei
+	Bribe *big.Int `json:"bribe"`
+	// BribeRatio is Bribe / Profit; only set if the profit is positive and in WETH
+	BribeRatio *float64 `json:"bribeRatio,omitempty"` //nolint:tagliatelle
+}
+
+// Sandwich is a victim swap front-run and back-run by the same attacker on the same pool.
+// Multiple victims can be sandwiched by the same pair of attacker transactions.
+type Sandwich struct {
+	BlockNumber uint64 `json:"blockNumber"` //nolint:tagliatelle
+	// Attacker is the sender of the front-run and back-run transactions
+	Attacker   common.Address `json:"attacker"`
+	Pool       common.Address `json:"pool"`
+	Protocol   string         `json:"protocol"`
+	FrontRunTx common.Hash    `json:"frontRunTx"` //nolint:tagliatelle
+	BackRunTx  common.Hash    `json:"backRunTx"`  //nolint:tagliatelle
+	// VictimTxs and Victims (their senders) are in block order
+	VictimTxs []common.Hash    `json:"victimTxs"` //nolint:tagliatelle
+	Victims   []common.Address `json:"victims"`
+	// Profit is the gross profit in ProfitToken, the token sold in the front-run, before gas and tips
+	ProfitToken common.Address `json:"profitToken"` //nolint:tagliatelle
+	Profit      *big.Int       `json:"profit"`
+}
+
+// SandwichFilter restricts the sandwiches returned; zero values mean unbounded
+type SandwichFilter struct {
+	FromBlock uint64         `json:"fromBlock"` //nolint:tagliatelle
+	ToBlock   uint64         `json:"toBlock"`   //nolint:tagliatelle
+	Attacker  common.Address `json:"attacker"`
+	Pool      common.Address `json:"pool"`
+	// Limit is the maximum number of results, the latest first
+	Limit uint64 `json:"limit"`
+}
+
+// StatsWindow restricts aggregations and exports to a range of blocks and/or a time range.
+// Block numbers and unix timestamps are inclusive; zero values mean unbounded.
+type StatsWindow struct {
+	FromBlock uint64 `json:"fromBlock"` //nolint:tagliatelle
+	ToBlock   uint64 `json:"toBlock"`   //nolint:tagliatelle
+	FromTime  uint64 `json:"fromTime"`  //nolint:tagliatelle
+	ToTime    uint64 `json:"toTime"`    //nolint:tagliatelle
+}
+
+// MinerStats aggregates the MEV paid to a single fee recipient (miner / builder).
+// Median and max are computed over the total MEV value of each of its blocks.
+type MinerStats struct {
+	Miner       common.Address `json:"miner"`
+	Blocks      uint64         `json:"blocks"`
+	Transfers   uint64         `json:"transfers"`
+	TotalValue  *big.Int       `json:"totalValue"`  //nolint:tagliatelle
+	MedianValue *big.Int       `json:"medianValue"` //nolint:tagliatelle
+	MaxValue    *big.Int       `json:"maxValue"`    //nolint:tagliatelle
+	// TotalValueUSD sums the USD values of the PricedBlocks blocks with a price; nil if none has one
+	TotalValueUSD *float64 `json:"totalValueUsd,omitempty"` //nolint:tagliatelle
+	PricedBlocks  uint64   `json:"pricedBlocks"`            //nolint:tagliatelle
+	// Share is the fraction of all MEV in the window paid to this miner
+	Share float64 `json:"share"`
+	// TokenTotals are the ERC-20 payments to this miner, per token; they are not part of the values above
+	TokenTotals []*TokenTotal `json:"tokenTotals,omitempty"` //nolint:tagliatelle
 }
 
 func NewNullInt64(i int64) sql.NullInt64 {
@@ -39,6 +139,38 @@ func NewNullString(s string) sql.NullString {
 	}
 }
 
+// hexArray stores addresses or hashes in a text array column
+type hexArray[T ~string] []T
+
+// Value implements driver.Valuer
+func (a hexArray[T]) Value() (driver.Value, error) {
+	if a == nil {
+		return pq.StringArray(nil).Value()
+	}
+	strs := make(pq.StringArray, 0, len(a))
+	for _, v := range a {
+		strs = append(strs, string(v))
+	}
+	return strs.Value()
+}
+
+// Scan implements sql.Scanner
+func (a *hexArray[T]) Scan(src any) error {
+	var strs pq.StringArray
+	if err := strs.Scan(src); err != nil {
+		return err
+	}
+	if strs == nil {
+		*a = nil
+		return nil
+	}
+	*a = make(hexArray[T], 0, len(strs))
+	for _, s := range strs {
+		*a = append(*a, T(s))
+	}
+	return nil
+}
+
 // NewNullTime returns a sql.NullTime with the given time.Time. If the time is
 // the zero value, the NullTime is invalid.
 func NewNullTime(t time.Time) sql.NullTime {
@@ -47,3 +179,93 @@ func NewNullTime(t time.Time) sql.NullTime {
 		Valid: t != time.Time{},
 	}
 }
+
+// Liquidation is a liquidation of an undercollateralized loan on a lending protocol
+type Liquidation struct {
+	BlockNumber uint64         `json:"blockNumber"` //nolint:tagliatelle
+	TxHash      common.Hash    `json:"txHash"`      //nolint:tagliatelle
+	Position    uint64         `json:"position"`
+	LogIndex    uint64         `json:"logIndex"` //nolint:tagliatelle
+	Protocol    string         `json:"protocol"`
+	Liquidator  common.Address `json:"liquidator"`
+	// Borrower is the liquidated account
+	Borrower common.Address `json:"borrower"`
+	// SeizedCollateral is in CollateralAsset, RepaidDebt in DebtAsset, in their smallest unit.
+	// On Compound both assets are cTokens, and the collateral is seized in cToken units.
+	CollateralAsset  common.Address `json:"collateralAsset"`  //nolint:tagliatelle
+	SeizedCollateral *big.Int       `json:"seizedCollateral"` //nolint:tagliatelle
+	DebtAsset        common.Address `json:"debtAsset"`        //nolint:tagliatelle
+	RepaidDebt       *big.Int       `json:"repaidDebt"`       //nolint:tagliatelle
+	// Bribe is the sum of the coinbase transfers of the tx, in wei; nil if it made none
+	Bribe *big.Int `json:"bribe,omitempty"`
+}
+
+// LiquidationFilter restricts the liquidations returned; zero values mean unbounded
+type LiquidationFilter struct {
+	FromBlock  uint64         `json:"fromBlock"` //nolint:tagliatelle
+	ToBlock    uint64         `json:"toBlock"`   //nolint:tagliatelle
+	Protocol   string         `json:"protocol"`
+	Liquidator common.Address `json:"liquidator"`
+	Borrower   common.Address `json:"borrower"`
+	// Limit is the maximum number of results, the latest first
+	Limit uint64 `json:"limit"`
+}
+
+// TokenPayment is an ERC-20 transfer to the miner of a block
+type TokenPayment struct {
+	BlockNumber uint64      `json:"blockNumber"` //nolint:tagliatelle
+	TxHash      common.Hash `json:"txHash"`      //nolint:tagliatelle
+	Position    uint64      `json:"position"`
+	LogIndex    uint64      `json:"logIndex"` //nolint:tagliatelle
+	// From is the sender of the tokens, not of the transaction
+	From  common.Address `json:"from"`
+	Token common.Address `json:"token"`
+	// Decimals is nil if the token doesn't implement decimals()
+	Decimals *uint8 `json:"decimals,omitempty"`
+	// Amount is in the smallest unit of Token
+	Amount *big.Int `json:"amount"`
+}
+
+// TokenTotal is the sum of the payments in a token
+type TokenTotal struct {
+	Token    common.Address `json:"token"`
+	Decimals *uint8         `json:"decimals,omitempty"`
+	Amount   *big.Int       `json:"amount"`
+	Payments uint64         `json:"payments"`
+}
+
+// TokenTotals sums payments per token, in the order the tokens were first paid
+func TokenTotals(payments []*TokenPayment) []*TokenTotal {
+	var totals []*TokenTotal
+	byToken := map[common.Address]*TokenTotal{}
+	for _, p := range payments {
+		total, ok := byToken[p.Token]
+		if !ok {
+			total = &TokenTotal{Token: p.Token, Decimals: p.Decimals, Amount: new(big.Int)}
+			byToken[p.Token] = total
+			totals = append(totals, total)
+		}
+		total.Amount.Add(total.Amount, p.Amount)
+		total.Payments++
+	}
+	return totals
+}
+
+// Bundle is a group of consecutive transactions likely submitted together, ending in a coinbase payment
+type Bundle struct {
+	BlockNumber uint64 `json:"blockNumber"` //nolint:tagliatelle
+	// Position is the index in the block of the first tx
+	Position uint64 `json:"position"`
+	// TxHashes are in block order; the last one pays the coinbase
+	TxHashes []common.Hash `json:"txHashes"` //nolint:tagliatelle
+	// Payment is the sum of the coinbase transfers of the txs, in wei
+	Payment *big.Int `json:"payment"`
+}
+
+// Label is a human readable name of an address, e.g. of a builder, searcher or protocol
+type Label struct {
+	Address common.Address `json:"address"`
+	Name    string         `json:"name"`
+	// Category is free form, e.g. "builder", "searcher" or "protocol"
+	Category string `json:"category,omitempty"`
+}
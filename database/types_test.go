@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/holisticode/mev-rpc/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,3 +18,42 @@ func TestNewNullTime(t *testing.T) {
 	require.True(t, nt1.Valid)
 	require.Equal(t, t1, nt1.Time)
 }
+
+func TestChecksummed(t *testing.T) {
+	const (
+		lower    common.Address = "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359"
+		checksum common.Address = "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"
+	)
+	tx := &MEVTransaction{
+		From:      lower,
+		To:        "0x1234",
+		Arbitrage: &Arbitrage{Path: []common.Address{lower, lower}, ProfitToken: lower},
+		ToLabel:   &Label{Address: lower, Name: "builder"},
+	}
+	block := &MEVBlock{
+		Miner:           lower,
+		MEVTransactions: []*MEVTransaction{tx},
+		Sandwiches:      []*Sandwich{{Attacker: lower, Victims: []common.Address{lower}}},
+		TokenTotals:     []*TokenTotal{{Token: lower}},
+	}
+
+	c := block.Checksummed()
+	require.Equal(t, checksum, c.Miner)
+	checksummedTx := c.MEVTransactions[0]
+	require.Equal(t, checksum, checksummedTx.From)
+	// malformed addresses are left as they are
+	require.Equal(t, common.Address("0x1234"), checksummedTx.To)
+	require.Equal(t, []common.Address{checksum, checksum}, checksummedTx.Arbitrage.Path)
+	require.Equal(t, checksum, checksummedTx.Arbitrage.ProfitToken)
+	require.Equal(t, checksum, checksummedTx.ToLabel.Address)
+	require.Equal(t, checksum, c.Sandwiches[0].Attacker)
+	require.Equal(t, []common.Address{checksum}, c.Sandwiches[0].Victims)
+	require.Equal(t, checksum, c.TokenTotals[0].Token)
+
+	// the original is untouched
+	require.Equal(t, lower, block.Miner)
+	require.Equal(t, lower, tx.From)
+	require.Equal(t, lower, tx.Arbitrage.Path[0])
+	require.Equal(t, lower, tx.ToLabel.Address)
+	require.Equal(t, lower, block.Sandwiches[0].Attacker)
+}
@@ -3,11 +3,39 @@ package vars
 
 import "github.com/holisticode/mev-rpc/common"
 
-var (
-	tablePrefix = common.GetEnv("DB_TABLE_PREFIX", "mev")
-	tableSuffix = common.GetEnv("DB_TABLE_SUFFIX", "dev")
+const (
+	DefaultTablePrefix = "mev"
+	DefaultTableSuffix = "dev"
+)
 
-	TableMigrations = tablePrefix + "_migrations" + tableSuffix
-	TableMEVBlocks  = tablePrefix + "_blocks_" + tableSuffix
-	TableMEVTxs     = tablePrefix + "_txs_" + tableSuffix
+var (
+	TableMigrations     string
+	TableMEVBlocks      string
+	TableMEVTxs         string
+	TableBackfillShards string
+	TableSandwiches     string
+	TableArbitrages     string
+	TableLiquidations   string
+	TableTokenPayments  string
+	TableBundles        string
+	TableLabels         string
 )
+
+func init() {
+	SetTableNames(common.GetEnv("DB_TABLE_PREFIX", DefaultTablePrefix), common.GetEnv("DB_TABLE_SUFFIX", DefaultTableSuffix))
+}
+
+// SetTableNames sets the prefix and suffix of all tables.
+// It must be called before the database is accessed.
+func SetTableNames(prefix, suffix string) {
+	TableMigrations = prefix + "_migrations" + suffix
+	TableMEVBlocks = prefix + "_blocks_" + suffix
+	TableMEVTxs = prefix + "_txs_" + suffix
+	TableBackfillShards = prefix + "_backfill_shards_" + suffix
+	TableSandwiches = prefix + "_sandwiches_" + suffix
+	TableArbitrages = prefix + "_arbitrages_" + suffix
+	TableLiquidations = prefix + "_liquidations_" + suffix
+	TableTokenPayments = prefix + "_token_payments_" + suffix
+	TableBundles = prefix + "_bundles_" + suffix
+	TableLabels = prefix + "_labels_" + suffix
+}
@@ -0,0 +1,148 @@
+// Package export writes the stored MEV data to files, to load it into notebooks and warehouses
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/holisticode/mev-rpc/database"
+)
+
+// Format is the file format of an export
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatNDJSON  Format = "ndjson"
+	FormatParquet Format = "parquet"
+)
+
+const (
+	DefaultChunkRows  = 1_000_000
+	DefaultChunkBytes = 256 << 20
+)
+
+var ErrUnknownFormat = errors.New("unknown export format, use csv, ndjson or parquet")
+
+// Opts configure where and how the files are written
+type Opts struct {
+	// Dir is where the files are written; it is created if needed
+	Dir    string
+	Format Format
+	// a new file is started once a file holds ChunkRows rows or ChunkBytes bytes; 0 means unbounded.
+	// A file can exceed ChunkBytes by one row, and the size of Parquet files is estimated before compression.
+	ChunkRows  uint64
+	ChunkBytes uint64
+}
+
+// Result lists the files written and the number of rows exported
+type Result struct {
+	Files []string
+	Rows  uint64
+}
+
+// ParseFormat checks that format is a known format
+func ParseFormat(format string) (Format, error) {
+	switch f := Format(format); f {
+	case FormatCSV, FormatNDJSON, FormatParquet:
+		return f, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+// Table streams the columns of the rows of table in window to files named <table>-<n>.<format> in opts.Dir.
+// The files of an earlier export of table in that format are removed first, so that no stale chunks are left.
+// A table without rows in window gets no file.
+func Table(
+	ctx context.Context,
+	source database.RowExporter,
+	table database.ExportTable,
+	columns []database.ExportColumn,
+	window *database.StatsWindow,
+	opts *Opts,
+) (res *Result, err error) {
+	if _, err := ParseFormat(string(opts.Format)); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := removeChunks(opts.Dir, table, opts.Format); err != nil {
+		return nil, err
+	}
+	res = &Result{}
+	var (
+		file *os.File
+		w    rowWriter
+		rows uint64
+	)
+	closeFile := func() error {
+		if file == nil {
+			return nil
+		}
+		err := errors.Join(w.Close(), file.Close())
+		file, w = nil, nil
+		return err
+	}
+	// the current file is closed in any case; on error, the files written are kept
+	defer func() {
+		err = errors.Join(err, closeFile())
+	}()
+
+	err = source.ExportRows(ctx, table, columns, window, func(row []any) error {
+		if file != nil && ((opts.ChunkRows > 0 && rows >= opts.ChunkRows) ||
+			(opts.ChunkBytes > 0 && uint64(w.Size()) >= opts.ChunkBytes)) { //nolint:gosec
+			if err := closeFile(); err != nil {
+				return err
+			}
+		}
+		if file == nil {
+			path := filepath.Join(opts.Dir, fmt.Sprintf("%s-%05d.%s", table, len(res.Files), opts.Format))
+			var err error
+			if file, err = os.Create(path); err != nil {
+				return err
+			}
+			if w, err = newRowWriter(opts.Format, file, columns, opts.ChunkBytes); err != nil {
+				_ = file.Close()
+				file = nil
+				return err
+			}
+			res.Files = append(res.Files, path)
+			rows = 0
+		}
+		if err := w.WriteRow(row); err != nil {
+			return err
+		}
+		rows++
+		res.Rows++
+		return nil
+	})
+	return res, err
+}
+
+// removeChunks removes the files named <table>-<n>.<format> in dir
+func removeChunks(dir string, table database.ExportTable, format Format) error {
+	prefix, suffix := string(table)+"-", "."+string(format)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		n := strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix)
+		if n == "" || strings.Trim(n, "0123456789") != "" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+// fakeSource serves rows like the DB, reusing the row passed to fn
+type fakeSource struct {
+	rows [][]any
+}
+
+func (f *fakeSource) ExportRows(
+	_ context.Context,
+	_ database.ExportTable,
+	columns []database.ExportColumn,
+	_ *database.StatsWindow,
+	fn func(row []any) error,
+) error {
+	row := make([]any, len(columns))
+	for _, r := range f.rows {
+		copy(row, r)
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func testColumns(t *testing.T) []database.ExportColumn {
+	t.Helper()
+	columns, err := database.ExportColumns(database.ExportBlocks, []string{"blocknumber", "miner", "flashbot", "total", "ethusd"})
+	require.NoError(t, err)
+	return columns
+}
+
+func testSource() *fakeSource {
+	return &fakeSource{rows: [][]any{
+		{int64(21_000_042), "0x8888", true, "1000000000000000000", 2500.5},
+		{int64(21_000_043), "0x9999,\"x\"", false, "42", nil},
+		{int64(21_000_044), "0x8888", false, "7", 2600.0},
+	}}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestExportCSV(t *testing.T) {
+	dir := t.TempDir()
+	res, err := Table(t.Context(), testSource(), database.ExportBlocks, testColumns(t), nil,
+		&Opts{Dir: dir, Format: FormatCSV})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), res.Rows)
+	require.Equal(t, []string{filepath.Join(dir, "blocks-00000.csv")}, res.Files)
+	require.Equal(t, `blocknumber,miner,flashbot,total,ethusd
+21000042,0x8888,true,1000000000000000000,2500.5
+21000043,"0x9999,""x""",false,42,
+21000044,0x8888,false,7,2600
+`, readFile(t, res.Files[0]))
+}
+
+func TestExportNDJSON(t *testing.T) {
+	dir := t.TempDir()
+	res, err := Table(t.Context(), testSource(), database.ExportBlocks, testColumns(t), nil,
+		&Opts{Dir: dir, Format: FormatNDJSON, ChunkRows: 2})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), res.Rows)
+	require.Len(t, res.Files, 2)
+	require.Equal(t, `{"blocknumber":21000042,"miner":"0x8888","flashbot":true,"total":"1000000000000000000","ethusd":2500.5}
+{"blocknumber":21000043,"miner":"0x9999,\"x\"","flashbot":false,"total":"42","ethusd":null}
+`, readFile(t, filepath.Join(dir, "blocks-00000.ndjson")))
+	require.Equal(t, `{"blocknumber":21000044,"miner":"0x8888","flashbot":false,"total":"7","ethusd":2600}
+`, readFile(t, filepath.Join(dir, "blocks-00001.ndjson")))
+}
+
+func TestExportChunkBytes(t *testing.T) {
+	dir := t.TempDir()
+	// the header and the first row fill the first chunk
+	res, err := Table(t.Context(), testSource(), database.ExportBlocks, testColumns(t), nil,
+		&Opts{Dir: dir, Format: FormatCSV, ChunkBytes: 60})
+	require.NoError(t, err)
+	require.Len(t, res.Files, 3)
+	for i, path := range res.Files {
+		lines := strings.Split(strings.TrimSpace(readFile(t, path)), "\n")
+		// every file has a header
+		require.Len(t, lines, 2, i)
+		require.Equal(t, "blocknumber,miner,flashbot,total,ethusd", lines[0])
+	}
+}
+
+func TestExportParquet(t *testing.T) {
+	dir := t.TempDir()
+	res, err := Table(t.Context(), testSource(), database.ExportBlocks, testColumns(t), nil,
+		&Opts{Dir: dir, Format: FormatParquet})
+	require.NoError(t, err)
+	require.Len(t, res.Files, 1)
+
+	f, err := local.NewLocalFileReader(res.Files[0])
+	require.NoError(t, err)
+	defer f.Close()
+	pr, err := reader.NewParquetReader(f, nil, 1)
+	require.NoError(t, err)
+	defer pr.ReadStop()
+	require.Equal(t, int64(3), pr.GetNumRows())
+	rows, err := pr.ReadByNumber(3)
+	require.NoError(t, err)
+	data, err := json.Marshal(rows)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"Blocknumber":21000042,"Miner":"0x8888","Flashbot":true,"Total":"1000000000000000000","Ethusd":2500.5},
+		{"Blocknumber":21000043,"Miner":"0x9999,\"x\"","Flashbot":false,"Total":"42","Ethusd":null},
+		{"Blocknumber":21000044,"Miner":"0x8888","Flashbot":false,"Total":"7","Ethusd":2600}
+	]`, string(data))
+}
+
+func TestExportNoRows(t *testing.T) {
+	res, err := Table(t.Context(), &fakeSource{}, database.ExportTxs, testColumns(t), nil,
+		&Opts{Dir: t.TempDir(), Format: FormatParquet})
+	require.NoError(t, err)
+	require.Empty(t, res.Files)
+
+	_, err = Table(t.Context(), &fakeSource{}, database.ExportTxs, testColumns(t), nil,
+		&Opts{Dir: t.TempDir(), Format: "xlsx"})
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+// TestExportRemovesStaleChunks() tests that the chunks of an earlier, larger export are removed
+func TestExportRemovesStaleChunks(t *testing.T) {
+	dir := t.TempDir()
+	res, err := Table(t.Context(), testSource(), database.ExportBlocks, testColumns(t), nil,
+		&Opts{Dir: dir, Format: FormatCSV, ChunkRows: 1})
+	require.NoError(t, err)
+	require.Len(t, res.Files, 3)
+	// other files are kept
+	for _, name := range []string{"txs-00000.csv", "blocks-00000.ndjson", "blocks-notes.csv"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	res, err = Table(t.Context(), testSource(), database.ExportBlocks, testColumns(t), nil,
+		&Opts{Dir: dir, Format: FormatCSV})
+	require.NoError(t, err)
+	require.Len(t, res.Files, 1)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	require.ElementsMatch(t, []string{"blocks-00000.csv", "txs-00000.csv", "blocks-00000.ndjson", "blocks-notes.csv"}, names)
+}
@@ -0,0 +1,198 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// rowWriter encodes rows into a single file
+type rowWriter interface {
+	WriteRow(row []any) error
+	// Size is the number of bytes of the file so far, including buffered ones
+	Size() int64
+	// Close flushes the file, but doesn't close the underlying writer
+	Close() error
+}
+
+func newRowWriter(format Format, w io.Writer, columns []database.ExportColumn, chunkBytes uint64) (rowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatNDJSON:
+		return newNDJSONWriter(w, columns)
+	case FormatParquet:
+		return newParquetWriter(w, columns, chunkBytes)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+// lineWriter writes rows encoded one by one, counting the bytes
+type lineWriter struct {
+	out  *bufio.Writer
+	size int64
+	buf  bytes.Buffer
+}
+
+func (w *lineWriter) writeBuf() error {
+	n, err := w.out.Write(w.buf.Bytes())
+	w.size += int64(n)
+	w.buf.Reset()
+	return err
+}
+
+func (w *lineWriter) Size() int64 {
+	return w.size
+}
+
+func (w *lineWriter) Close() error {
+	return w.out.Flush()
+}
+
+// csvWriter writes a header with the column names, then one record per row; NULL is an empty field
+type csvWriter struct {
+	lineWriter
+	enc    *csv.Writer
+	fields []string
+}
+
+func newCSVWriter(w io.Writer, columns []database.ExportColumn) (*csvWriter, error) {
+	cw := &csvWriter{lineWriter: lineWriter{out: bufio.NewWriter(w)}, fields: make([]string, len(columns))}
+	cw.enc = csv.NewWriter(&cw.buf)
+	for i, c := range columns {
+		cw.fields[i] = c.Name
+	}
+	if err := cw.writeFields(); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (w *csvWriter) WriteRow(row []any) error {
+	for i, v := range row {
+		w.fields[i] = formatValue(v)
+	}
+	return w.writeFields()
+}
+
+func (w *csvWriter) writeFields() error {
+	if err := w.enc.Write(w.fields); err != nil {
+		return err
+	}
+	w.enc.Flush()
+	if err := w.enc.Error(); err != nil {
+		return err
+	}
+	return w.writeBuf()
+}
+
+// formatValue formats a value of a CSV field
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+// ndjsonWriter writes a JSON object per line, with the keys in the order of the columns
+type ndjsonWriter struct {
+	lineWriter
+	keys [][]byte
+}
+
+func newNDJSONWriter(w io.Writer, columns []database.ExportColumn) (*ndjsonWriter, error) {
+	nw := &ndjsonWriter{lineWriter: lineWriter{out: bufio.NewWriter(w)}}
+	for _, c := range columns {
+		key, err := json.Marshal(c.Name)
+		if err != nil {
+			return nil, err
+		}
+		nw.keys = append(nw.keys, key)
+	}
+	return nw, nil
+}
+
+func (w *ndjsonWriter) WriteRow(row []any) error {
+	w.buf.WriteByte('{')
+	for i, v := range row {
+		if i > 0 {
+			w.buf.WriteByte(',')
+		}
+		w.buf.Write(w.keys[i])
+		w.buf.WriteByte(':')
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		w.buf.Write(value)
+	}
+	w.buf.WriteString("}\n")
+	return w.writeBuf()
+}
+
+// parquetWriter writes a Parquet file with a nullable column per exported column
+type parquetWriter struct {
+	pw *writer.CSVWriter
+}
+
+// maxRowGroupSize bounds the memory used to buffer a row group
+const maxRowGroupSize = 64 << 20
+
+func newParquetWriter(w io.Writer, columns []database.ExportColumn, chunkBytes uint64) (*parquetWriter, error) {
+	schema := make([]string, 0, len(columns))
+	for _, c := range columns {
+		var typ string
+		switch c.Type {
+		case database.ColumnInt:
+			typ = "type=INT64"
+		case database.ColumnFloat:
+			typ = "type=DOUBLE"
+		case database.ColumnBool:
+			typ = "type=BOOLEAN"
+		default:
+			typ = "type=BYTE_ARRAY, convertedtype=UTF8"
+		}
+		schema = append(schema, "name="+c.Name+", "+typ+", repetitiontype=OPTIONAL")
+	}
+	pw, err := writer.NewCSVWriterFromWriter(schema, w, 1)
+	if err != nil {
+		return nil, err
+	}
+	pw.RowGroupSize = maxRowGroupSize
+	if chunkBytes > 0 && chunkBytes < maxRowGroupSize {
+		pw.RowGroupSize = int64(chunkBytes)
+	}
+	return &parquetWriter{pw: pw}, nil
+}
+
+func (w *parquetWriter) WriteRow(row []any) error {
+	// rows are buffered until their row group is written, so they can't be reused by the caller
+	rec := make([]any, len(row))
+	copy(rec, row)
+	return w.pw.Write(rec)
+}
+
+// Size estimates the size of the file: the buffered rows are counted uncompressed
+func (w *parquetWriter) Size() int64 {
+	return w.pw.Offset + w.pw.Size + w.pw.ObjsSize
+}
+
+func (w *parquetWriter) Close() error {
+	return w.pw.WriteStop()
+}
@@ -0,0 +1,89 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/flashbots/go-utils/rpcserver"
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/holisticode/mev-rpc/metrics"
+)
+
+// AdminRPCPath is where the admin JSON-RPC methods are served, if an admin token is configured
+const AdminRPCPath = "/admin"
+
+const (
+	RPCModuleLabels      = "mev_admin_labels"
+	RPCModuleSetLabel    = "mev_admin_setLabel"
+	RPCModuleDeleteLabel = "mev_admin_deleteLabel"
+)
+
+// newAdminRPCHandler creates the JSON-RPC handler of the admin methods, which require cfg.AdminToken
+func newAdminRPCHandler(cfg *HTTPServerConfig, metricsSrv *metrics.MetricsServer) (http.Handler, error) {
+	mevServer := &MEVJSONRPCServer{
+		dbService: cfg.DBService,
+		log:       cfg.Log,
+	}
+	methods := map[string]any{
+		RPCModuleLabels:      mevServer.handleLabels,
+		RPCModuleSetLabel:    mevServer.handleSetLabel,
+		RPCModuleDeleteLabel: mevServer.handleDeleteLabel,
+	}
+	handler, err := rpcserver.NewJSONRPCHandler(methods, rpcserver.JSONRPCHandlerOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("failed creating admin JSONRPCHandler: %w", err)
+	}
+	return requireToken(cfg.AdminToken, newRPCInstrumentation(metricsSrv, methods).middleware(rpcErrorCodes(handler))), nil
+}
+
+// requireToken rejects requests without the bearer token
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleLabels() returns all address labels
+func (s *MEVJSONRPCServer) handleLabels(ctx context.Context) ([]*database.Label, error) {
+	s.log.Debug("MEVJSONRPCServer handleLabels")
+	labels, err := s.dbService.GetLabels(ctx)
+	if err != nil {
+		return nil, s.toRPCError(ctx, err, "")
+	}
+	return labels, nil
+}
+
+// handleSetLabel() creates or replaces the label of an address, and returns it normalized
+func (s *MEVJSONRPCServer) handleSetLabel(ctx context.Context, label *database.Label) (*database.Label, error) {
+	s.log.Debug("MEVJSONRPCServer handleSetLabel", "label", label)
+	if err := database.ValidateLabel(label); err != nil {
+		return nil, s.toRPCError(ctx, err, "")
+	}
+	if err := s.dbService.SetLabel(ctx, label); err != nil {
+		return nil, s.toRPCError(ctx, err, "")
+	}
+	s.log.Info("Label set", "address", label.Address, "name", label.Name, "category", label.Category)
+	return label, nil
+}
+
+// handleDeleteLabel() removes the label of an address
+func (s *MEVJSONRPCServer) handleDeleteLabel(ctx context.Context, address string) (bool, error) {
+	s.log.Debug("MEVJSONRPCServer handleDeleteLabel", "address", address)
+	if err := database.ValidateAddress(address); err != nil {
+		return false, s.toRPCError(ctx, err, MsgLabelNotFound)
+	}
+	if err := s.dbService.DeleteLabel(ctx, address); err != nil {
+		return false, s.toRPCError(ctx, err, MsgLabelNotFound)
+	}
+	s.log.Info("Label deleted", "address", address)
+	return true, nil
+}
@@ -0,0 +1,113 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/holisticode/mev-rpc/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const testAdminToken = "s3cret"
+
+// TestAdminRPC() tests the label admin methods, and that they require the admin token
+func TestAdminRPC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	srv, err := New(&HTTPServerConfig{
+		DBService:  mockStorage,
+		Log:        getTestLogger(),
+		AdminToken: testAdminToken,
+	})
+	require.NoError(t, err)
+
+	const builder = "0x95222290dd7278aa3ddd389cc1e1d165cc4bafe5"
+	label := &database.Label{Address: builder, Name: "beaverbuild", Category: "builder"}
+	mockStorage.EXPECT().SetLabel(gomock.Any(), label).Return(nil)
+	mockStorage.EXPECT().GetLabels(gomock.Any()).Return([]*database.Label{label}, nil)
+	mockStorage.EXPECT().DeleteLabel(gomock.Any(), builder).Return(nil)
+	mockStorage.EXPECT().DeleteLabel(gomock.Any(), builder).Return(fmt.Errorf("%w: no label", database.ErrNotFound))
+
+	doRequest := func(token, method, params string) *httptest.ResponseRecorder {
+		jsonReq := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": [%s]}`, method, params)
+		req, err := http.NewRequest(http.MethodPost, AdminRPCPath, bytes.NewReader([]byte(jsonReq)))
+		require.NoError(t, err)
+		req.Header.Add("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Add("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		srv.getRouter().ServeHTTP(rr, req)
+		return rr
+	}
+	decode := func(rr *httptest.ResponseRecorder) map[string]json.RawMessage {
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		return resp
+	}
+
+	{ // the token is required
+		require.Equal(t, http.StatusUnauthorized, doRequest("", RPCModuleLabels, "").Code)
+		require.Equal(t, http.StatusUnauthorized, doRequest("wrong", RPCModuleLabels, "").Code)
+	}
+
+	{ // set normalizes the label
+		resp := decode(doRequest(testAdminToken, RPCModuleSetLabel,
+			`{"address": "0x95222290DD7278AA3DDD389CC1E1D165CC4BAFE5", "name": " beaverbuild ", "category": "Builder"}`))
+		var control *database.Label
+		require.NoError(t, json.Unmarshal(resp["result"], &control))
+		require.Equal(t, label, control)
+	}
+
+	{ // an invalid label never reaches the storage
+		resp := decode(doRequest(testAdminToken, RPCModuleSetLabel, `{"address": "0x1234", "name": "foo"}`))
+		requireRPCError(t, resp, CodeInvalidParams, "")
+	}
+
+	{ // list
+		resp := decode(doRequest(testAdminToken, RPCModuleLabels, ""))
+		var control []*database.Label
+		require.NoError(t, json.Unmarshal(resp["result"], &control))
+		require.Equal(t, []*database.Label{label}, control)
+	}
+
+	{ // delete, twice
+		resp := decode(doRequest(testAdminToken, RPCModuleDeleteLabel, `"`+builder+`"`))
+		require.JSONEq(t, "true", string(resp["result"]))
+		resp = decode(doRequest(testAdminToken, RPCModuleDeleteLabel, `"`+builder+`"`))
+		requireRPCError(t, resp, CodeResourceNotFound, MsgLabelNotFound)
+	}
+
+	{ // admin methods are not served on the public path
+		jsonReq := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": []}`, RPCModuleLabels)
+		req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(jsonReq)))
+		require.NoError(t, err)
+		req.Header.Add("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		srv.getRouter().ServeHTTP(rr, req)
+		resp := decode(rr)
+		require.Contains(t, resp, "error")
+	}
+}
+
+// TestAdminRPCDisabled() tests that the admin methods are not served without a token
+func TestAdminRPCDisabled(t *testing.T) {
+	srv, err := New(&HTTPServerConfig{
+		DBService: mocks.NewMockMEVTraceStorage(gomock.NewController(t)),
+		Log:       getTestLogger(),
+	})
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, AdminRPCPath, bytes.NewReader([]byte(`{}`)))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	srv.getRouter().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -1,9 +1,11 @@
 package httpserver
 
 import (
+	"context"
 	"net/http"
 	"time"
 
+	"github.com/holisticode/mev-rpc/blocktrace"
 	"github.com/holisticode/mev-rpc/metrics"
 )
 
@@ -33,9 +35,63 @@ func (srv *Server) handleReadinessCheck(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	ctx, cancel := context.WithTimeout(r.Context(), readinessCheckTimeout)
+	defer cancel()
+
+	// we can't serve queries without the DB
+	if srv.cfg.DBService != nil {
+		if err := srv.cfg.DBService.Ping(ctx); err != nil {
+			srv.log.Warn("Readiness check: database unreachable", "err", err)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	// we don't want to serve stale data; if the data is indexed by another process
+	// (no tracer runs in this process, or it stands by), we can only tell how old it is
+	var status blocktrace.SyncStatus
+	if srv.cfg.SyncStatus != nil {
+		status = srv.cfg.SyncStatus.SyncStatus()
+	}
+	switch {
+	case srv.cfg.SyncStatus == nil || status.Standby:
+		if srv.cfg.DBService != nil && !srv.storedDataFresh(ctx) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+	case status.CatchingUp || status.Lag > srv.cfg.MaxBlockLag:
+		srv.log.Warn("Readiness check: tracer not in sync with chain head",
+			"catchingUp", status.CatchingUp,
+			"lag", status.Lag,
+			"lastProcessedBlock", status.LastProcessedBlock,
+			"chainHead", status.ChainHead)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
 	w.WriteHeader(http.StatusOK)
 }
 
+// storedDataFresh is true if the latest stored block was produced at most MaxBlockLag slots
+// before the chain head, allowing for one slot to index the head block
+func (srv *Server) storedDataFresh(ctx context.Context) bool {
+	blockTime, err := srv.cfg.DBService.LatestBlockTime(ctx)
+	if err != nil {
+		srv.log.Warn("Readiness check: failed to get the latest stored block", "err", err)
+		return false
+	}
+	age := time.Since(time.Unix(int64(blockTime), 0)) //nolint:gosec
+	maxAge := time.Duration(srv.cfg.MaxBlockLag+1) * SlotDuration
+	if age > maxAge {
+		srv.log.Warn("Readiness check: stored data is stale",
+			"latestBlockTime", blockTime,
+			"age", age,
+			"maxAge", maxAge)
+		return false
+	}
+	return true
+}
+
 func (srv *Server) handleDrain(w http.ResponseWriter, r *http.Request) {
 	if wasReady := srv.isReady.Swap(false); !wasReady {
 		return
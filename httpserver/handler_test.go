@@ -1,6 +1,7 @@
 package httpserver
 
 import (
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
@@ -8,7 +9,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
+	"github.com/holisticode/mev-rpc/blocktrace"
 	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/holisticode/mev-rpc/mocks"
 	"github.com/stretchr/testify/require"
 )
 
@@ -112,3 +117,112 @@ func Test_Handlers_Simple(t *testing.T) {
 		require.Equal(t, http.StatusOK, rr.Code)
 	}
 }
+
+type fakeSyncStatus blocktrace.SyncStatus
+
+func (f fakeSyncStatus) SyncStatus() blocktrace.SyncStatus {
+	return blocktrace.SyncStatus(f)
+}
+
+// Test_Handlers_Readiness_Dependencies tests that readiness reflects DB connectivity, tracer lag and, for standbys, the stored data
+func Test_Handlers_Readiness_Dependencies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+
+	cfg := &HTTPServerConfig{
+		DBService:   mockStorage,
+		SyncStatus:  fakeSyncStatus{ChainHead: 100, LastProcessedBlock: 100},
+		MaxBlockLag: 5,
+		Log:         getTestLogger(),
+	}
+	srv, err := New(cfg)
+	require.NoError(t, err)
+
+	checkReady := func(expected int) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		srv.getRouter().ServeHTTP(rr, req)
+		require.Equal(t, expected, rr.Code)
+	}
+
+	// all good
+	mockStorage.EXPECT().Ping(gomock.Any()).Return(nil)
+	checkReady(http.StatusOK)
+
+	// DB unreachable
+	mockStorage.EXPECT().Ping(gomock.Any()).Return(errors.New("connection refused"))
+	checkReady(http.StatusServiceUnavailable)
+
+	// tracer behind, but within the configured lag
+	cfg.SyncStatus = fakeSyncStatus{ChainHead: 105, LastProcessedBlock: 100, Lag: 5}
+	mockStorage.EXPECT().Ping(gomock.Any()).Return(nil)
+	checkReady(http.StatusOK)
+
+	// tracer too far behind
+	cfg.SyncStatus = fakeSyncStatus{ChainHead: 106, LastProcessedBlock: 100, Lag: 6}
+	mockStorage.EXPECT().Ping(gomock.Any()).Return(nil)
+	checkReady(http.StatusServiceUnavailable)
+
+	// tracer still catching up after start
+	cfg.SyncStatus = fakeSyncStatus{CatchingUp: true}
+	mockStorage.EXPECT().Ping(gomock.Any()).Return(nil)
+	checkReady(http.StatusServiceUnavailable)
+
+	// a standby tracer doesn't index, another replica does; the stored data tells whether it keeps up
+	cfg.SyncStatus = fakeSyncStatus{CatchingUp: true, Standby: true}
+	mockStorage.EXPECT().Ping(gomock.Any()).Return(nil)
+	mockStorage.EXPECT().LatestBlockTime(gomock.Any()).Return(uint64(time.Now().Unix()), nil) //nolint:gosec
+	checkReady(http.StatusOK)
+	mockStorage.EXPECT().Ping(gomock.Any()).Return(nil)
+	mockStorage.EXPECT().LatestBlockTime(gomock.Any()).Return(uint64(time.Now().Add(-time.Hour).Unix()), nil) //nolint:gosec
+	checkReady(http.StatusServiceUnavailable)
+
+	// a lag of 0 requires the tracer to be at the chain head
+	cfg.MaxBlockLag = 0
+	cfg.SyncStatus = fakeSyncStatus{ChainHead: 101, LastProcessedBlock: 100, Lag: 1}
+	mockStorage.EXPECT().Ping(gomock.Any()).Return(nil)
+	checkReady(http.StatusServiceUnavailable)
+	cfg.SyncStatus = fakeSyncStatus{ChainHead: 101, LastProcessedBlock: 101}
+	mockStorage.EXPECT().Ping(gomock.Any()).Return(nil)
+	checkReady(http.StatusOK)
+}
+
+// Test_Handlers_Readiness_StoredData tests that without a tracer, readiness reflects the age of the stored data
+func Test_Handlers_Readiness_StoredData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+
+	cfg := &HTTPServerConfig{
+		DBService:   mockStorage,
+		MaxBlockLag: 5,
+		Log:         getTestLogger(),
+	}
+	srv, err := New(cfg)
+	require.NoError(t, err)
+
+	checkReady := func(blockTime uint64, blockErr error, expected int) {
+		t.Helper()
+		mockStorage.EXPECT().Ping(gomock.Any()).Return(nil)
+		mockStorage.EXPECT().LatestBlockTime(gomock.Any()).Return(blockTime, blockErr)
+		req, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		srv.getRouter().ServeHTTP(rr, req)
+		require.Equal(t, expected, rr.Code)
+	}
+	blocksAgo := func(n int) uint64 {
+		return uint64(time.Now().Add(-time.Duration(n) * SlotDuration).Unix()) //nolint:gosec
+	}
+
+	// the head block is stored one slot after it was produced
+	checkReady(blocksAgo(0), nil, http.StatusOK)
+	checkReady(blocksAgo(5), nil, http.StatusOK)
+	// the indexer is too far behind
+	checkReady(blocksAgo(7), nil, http.StatusServiceUnavailable)
+	// nothing stored yet
+	checkReady(0, database.ErrNotFound, http.StatusServiceUnavailable)
+}
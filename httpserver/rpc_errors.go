@@ -0,0 +1,140 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/flashbots/go-utils/rpcserver"
+	"github.com/holisticode/mev-rpc/database"
+)
+
+// JSON-RPC error codes returned by our methods, following EIP-1474
+const (
+	CodeInvalidParams       = -32602
+	CodeInternalError       = -32603
+	CodeResourceNotFound    = -32001
+	CodeResourceUnavailable = -32002
+)
+
+// Stable error messages, which don't leak internals
+const (
+	MsgTxNotFound     = "transaction not found"
+	MsgBlockNotFound  = "block not found"
+	MsgBundleNotFound = "bundle not found"
+	MsgLabelNotFound  = "label not found"
+	MsgUnavailable    = "service temporarily unavailable"
+	MsgInternalError  = "internal error"
+	MsgInvalidParams  = "invalid params"
+)
+
+// rpcError is an error to be returned to the client with a specific JSON-RPC code
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+type rpcErrorKey struct{}
+
+// rpcErrorHolder is passed down the request context, so that the methods can set the
+// error code of their response. rpcserver.JSONRPCHandler always responds to method
+// errors with rpcserver.CodeCustomError.
+type rpcErrorHolder struct {
+	err *rpcError
+}
+
+// toRPCError maps storage errors to JSON-RPC errors, and registers the error code
+// for the response; notFoundMsg is the message to be returned if the data doesn't exist
+func (s *MEVJSONRPCServer) toRPCError(ctx context.Context, err error, notFoundMsg string) error {
+	var rpcErr *rpcError
+	switch {
+	case errors.As(err, &rpcErr):
+	case errors.Is(err, database.ErrInvalidInput):
+		// validation messages are ours, so they can be returned as they are
+		rpcErr = &rpcError{Code: CodeInvalidParams, Message: err.Error()}
+	case errors.Is(err, database.ErrNotFound):
+		rpcErr = &rpcError{Code: CodeResourceNotFound, Message: notFoundMsg}
+	case errors.Is(err, database.ErrUnavailable):
+		s.log.Error("Storage unavailable", "err", err)
+		rpcErr = &rpcError{Code: CodeResourceUnavailable, Message: MsgUnavailable}
+	default:
+		s.log.Error("Failed to serve request", "err", err)
+		rpcErr = &rpcError{Code: CodeInternalError, Message: MsgInternalError}
+	}
+	if holder, ok := ctx.Value(rpcErrorKey{}).(*rpcErrorHolder); ok {
+		holder.err = rpcErr
+	}
+	return rpcErr
+}
+
+// bufferedResponseWriter holds back the response, so that it can be rewritten
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+// rpcErrorCodes is a middleware which replaces the generic error code of
+// method errors with the code set by the method via toRPCError. A generic
+// error without a code comes from params which couldn't be decoded, as
+// every method error goes through toRPCError.
+func rpcErrorCodes(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		holder := &rpcErrorHolder{}
+		r = r.WithContext(context.WithValue(r.Context(), rpcErrorKey{}, holder))
+		buf := &bufferedResponseWriter{header: w.Header(), status: http.StatusOK}
+		next.ServeHTTP(buf, r)
+
+		body := buf.body.Bytes()
+		if holder.err != nil {
+			body = rewriteErrorCode(body, holder.err)
+		} else {
+			body = rewriteErrorCode(body, &rpcError{Code: CodeInvalidParams, Message: MsgInvalidParams})
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(buf.status)
+		_, _ = w.Write(body)
+	})
+}
+
+// rewriteErrorCode replaces the error of a JSON-RPC response;
+// if the response can't be decoded, it is returned as it is
+func rewriteErrorCode(body []byte, rpcErr *rpcError) []byte {
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return body
+	}
+	var respErr rpcError
+	if err := json.Unmarshal(resp["error"], &respErr); err != nil || respErr.Code != rpcserver.CodeCustomError {
+		return body
+	}
+	newErr, err := json.Marshal(rpcErr)
+	if err != nil {
+		return body
+	}
+	resp["error"] = newErr
+	newBody, err := json.Marshal(resp)
+	if err != nil {
+		return body
+	}
+	return append(newBody, '\n')
+}
@@ -0,0 +1,155 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/flashbots/go-utils/rpcserver"
+	"github.com/holisticode/mev-rpc/metrics"
+	"github.com/holisticode/mev-rpc/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// metric names exported by the JSON-RPC server
+const (
+	MetricRPCRequests        = "rpc_requests"
+	MetricRPCRequestErrors   = "rpc_request_errors"
+	MetricRPCRequestDuration = "rpc_request_duration"
+)
+
+// unknownMethod is used as label for requests to methods we don't serve,
+// so that arbitrary method names can't blow up the metrics' cardinality
+const unknownMethod = "unknown"
+
+// rpcInstrumentation records metrics (per method request counts, errors by
+// JSON-RPC error code, latency) and a tracing span for every request passing
+// through the JSON-RPC handler
+type rpcInstrumentation struct {
+	// metricsSrv can be nil, in which case no metrics are recorded
+	metricsSrv *metrics.MetricsServer
+	methods    map[string]struct{}
+}
+
+// jsonRPCEnvelope contains the fields of requests and responses we need for metrics
+type jsonRPCEnvelope struct {
+	Method string `json:"method"`
+	Error  *struct {
+		Code int `json:"code"`
+	} `json:"error"`
+}
+
+// errorCode returns the JSON-RPC error code of a response, and whether it is an error at all.
+// Responses which aren't JSON-RPC (e.g. for a wrong HTTP method) are errors with code 0.
+func errorCode(body []byte) (int, bool) {
+	var resp jsonRPCEnvelope
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, true
+	}
+	if resp.Error == nil {
+		return 0, false
+	}
+	return resp.Error.Code, true
+}
+
+// responseRecorder writes the response through, while keeping a copy of the body
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func newRPCInstrumentation(metricsSrv *metrics.MetricsServer, methods map[string]any) *rpcInstrumentation {
+	m := &rpcInstrumentation{
+		metricsSrv: metricsSrv,
+		methods:    make(map[string]struct{}, len(methods)),
+	}
+	for name := range methods {
+		m.methods[name] = struct{}{}
+	}
+	return m
+}
+
+// middleware wraps the JSON-RPC handler. It peeks into the request for the method
+// and into the response for the error code, without altering either.
+// Trace context sent by the client is propagated into the request span.
+func (m *rpcInstrumentation) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		// the body is consumed by the handler, so we need to buffer it.
+		// It is bounded like rpcserver does, since we read it first.
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(rpcserver.DefaultMaxRequestBodySizeBytes)))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var req jsonRPCEnvelope
+		_ = json.Unmarshal(body, &req)
+		method := req.Method
+		if _, ok := m.methods[method]; !ok {
+			method = unknownMethod
+		}
+		methodAttr := attribute.String("method", method)
+
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Start(ctx, "jsonrpc "+method, trace.SpanKindServer,
+			attribute.String("rpc.system", "jsonrpc"),
+			attribute.String("rpc.method", method))
+		defer span.End()
+
+		rec := &responseRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		code, isErr := errorCode(rec.body.Bytes())
+		if isErr {
+			span.SetAttributes(attribute.Int("rpc.jsonrpc.error_code", code))
+			span.SetStatus(codes.Error, "JSON-RPC error "+strconv.Itoa(code))
+		}
+
+		if m.metricsSrv == nil {
+			return
+		}
+
+		m.metricsSrv.Int64Counter(
+			MetricRPCRequests,
+			"number of JSON-RPC requests",
+			"",
+		).Add(ctx, 1, metric.WithAttributes(methodAttr))
+
+		m.metricsSrv.Float64Histogram(
+			MetricRPCRequestDuration,
+			"JSON-RPC request handling duration",
+			metrics.UomMilliseconds,
+			metrics.BucketsQueryDuration...,
+		).Record(ctx, float64(time.Since(start).Microseconds())/1000, metric.WithAttributes(methodAttr))
+
+		if !isErr {
+			return
+		}
+		m.metricsSrv.Int64Counter(
+			MetricRPCRequestErrors,
+			"number of JSON-RPC requests which returned an error, by JSON-RPC error code (0 if not a JSON-RPC error)",
+			"",
+		).Add(ctx, 1, metric.WithAttributes(methodAttr, attribute.String("code", strconv.Itoa(code))))
+	})
+}
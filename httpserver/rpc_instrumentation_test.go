@@ -0,0 +1,126 @@
+package httpserver
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flashbots/go-utils/rpcserver"
+	"github.com/golang/mock/gomock"
+	"github.com/holisticode/mev-rpc/common"
+	"github.com/holisticode/mev-rpc/database"
+	"github.com/holisticode/mev-rpc/metrics"
+	"github.com/holisticode/mev-rpc/mocks"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestRPCMetrics() tests that requests, errors and latency are recorded per method
+func TestRPCMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	metricsSrv, err := metrics.New(common.PackageName, "")
+	require.NoError(t, err)
+	srv, err := New(&HTTPServerConfig{
+		DBService:  mockStorage,
+		MetricsSrv: metricsSrv,
+		Log:        getTestLogger(),
+	})
+	require.NoError(t, err)
+
+	mockStorage.EXPECT().GetMEVTx(gomock.Any(), testTxHash1).Return(createMEVTx(testTxHash1), nil)
+	mockStorage.EXPECT().GetMEVTx(gomock.Any(), testTxHash2).Return(nil, database.ErrNotFound)
+
+	doRequest := func(method, param string) {
+		jsonReq := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": ["%s"]}`, method, param)
+		req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(jsonReq)))
+		require.NoError(t, err)
+		req.Header.Add("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		srv.getRouter().ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+	}
+	doRequest(RPCModuleByTX, testTxHash1)
+	doRequest(RPCModuleByTX, testTxHash2)
+	doRequest("eth_doesNotExist", testTxHash1)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rr := httptest.NewRecorder()
+	metricsSrv.Handler().ServeHTTP(rr, req)
+	body := rr.Body.String()
+	require.Regexp(t, `rpc_requests_total\{method="mev_rpc_tx".*\} 2`, body)
+	require.Regexp(t, `rpc_requests_total\{method="unknown".*\} 1`, body)
+	require.Regexp(t, `rpc_request_errors_total\{code="-32001",method="mev_rpc_tx".*\} 1`, body)
+	require.Regexp(t, `rpc_request_errors_total\{code="-32601",method="unknown".*\} 1`, body)
+	require.Regexp(t, `rpc_request_duration_milliseconds_count\{method="mev_rpc_tx".*\} 2`, body)
+}
+
+// TestRPCTracing() tests that a server span is recorded per request, joining the caller's trace
+func TestRPCTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(trace.NewNoopTracerProvider()) })
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	srv, err := New(&HTTPServerConfig{
+		DBService: mockStorage,
+		Log:       getTestLogger(),
+	})
+	require.NoError(t, err)
+
+	mockStorage.EXPECT().GetMEVTx(gomock.Any(), testTxHash2).Return(nil, database.ErrNotFound)
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	jsonReq := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": ["%s"]}`, RPCModuleByTX, testTxHash2)
+	req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(jsonReq)))
+	require.NoError(t, err)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	rr := httptest.NewRecorder()
+	srv.getRouter().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "jsonrpc "+RPCModuleByTX, spans[0].Name())
+	require.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	require.Equal(t, traceID, spans[0].SpanContext().TraceID().String())
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+// TestRPCBodyLimit() tests that oversized requests are refused before being buffered
+func TestRPCBodyLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	srv, err := New(&HTTPServerConfig{
+		DBService:  mockStorage,
+		Log:        getTestLogger(),
+		AdminToken: testAdminToken,
+	})
+	require.NoError(t, err)
+
+	jsonReq := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": ["%s"]}`,
+		RPCModuleByTX, strings.Repeat("0", rpcserver.DefaultMaxRequestBodySizeBytes))
+	for _, path := range []string{"/", AdminRPCPath} {
+		req, err := http.NewRequest(http.MethodPost, path, strings.NewReader(jsonReq))
+		require.NoError(t, err)
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Authorization", "Bearer "+testAdminToken)
+		rr := httptest.NewRecorder()
+		srv.getRouter().ServeHTTP(rr, req)
+		require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code, path)
+	}
+}
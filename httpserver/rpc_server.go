@@ -7,14 +7,28 @@ import (
 	"net/http"
 
 	"github.com/flashbots/go-utils/rpcserver"
+	"github.com/holisticode/mev-rpc/common"
 	"github.com/holisticode/mev-rpc/database"
+	"github.com/holisticode/mev-rpc/metrics"
 )
 
 const (
-	RPCModuleByTX    = "mev_rpc_tx"
-	RPCModuleByBlock = "mev_rpc_block"
+	RPCModuleByTX         = "mev_rpc_tx"
+	RPCModuleByBlock      = "mev_rpc_block"
+	RPCModuleMinerStats   = "mev_rpc_minerStats"
+	RPCModuleSandwiches   = "mev_rpc_sandwiches"
+	RPCModuleLiquidations = "mev_rpc_liquidations"
+	RPCModuleBundle       = "mev_rpc_bundle"
 )
 
+// ResponseOpts are optional parameters changing how results are encoded
+type ResponseOpts struct {
+	// Checksum returns addresses in their EIP-55 mixed case encoding instead of lower case
+	Checksum bool `json:"checksum"`
+}
+
+var ErrInvalidWindow = fmt.Errorf("%w: window range start is after range end", database.ErrInvalidInput)
+
 // MEVJSONRPCServer is used to run thiw work task's RPC server
 type MEVJSONRPCServer struct {
 	*http.Server
@@ -23,21 +37,11 @@ type MEVJSONRPCServer struct {
 	log       *slog.Logger
 }
 
-// NewJSONRPCServer creates a new one
+// NewJSONRPCServer creates a new one, serving only the JSON-RPC handler
 func NewJSONRPCServer(cfg *HTTPServerConfig) (*http.Server, error) {
-	mevServer := &MEVJSONRPCServer{
-		dbService: cfg.DBService,
-		log:       cfg.Log,
-	}
-	// our 2 methods supported by this RPC server
-	methods := map[string]any{
-		RPCModuleByBlock: mevServer.handleByBlock,
-		RPCModuleByTX:    mevServer.handleByTx,
-	}
-	opts := rpcserver.JSONRPCHandlerOpts{}
-	handler, err := rpcserver.NewJSONRPCHandler(methods, opts)
+	mevServer, handler, err := newJSONRPCHandler(cfg, cfg.MetricsSrv)
 	if err != nil {
-		return nil, fmt.Errorf("failed creating JSONRPCHandler: %w", err)
+		return nil, err
 	}
 	s := &http.Server{
 		Addr:         cfg.ListenAddr,
@@ -49,14 +53,157 @@ func NewJSONRPCServer(cfg *HTTPServerConfig) (*http.Server, error) {
 	return s, nil
 }
 
-// handleByTx() is simple, just calls the DB Service with the appropriate method
-func (s *MEVJSONRPCServer) handleByTx(ctx context.Context, tx string) (*database.MEVTransaction, error) {
+// newJSONRPCHandler creates the JSON-RPC handler, which can be mounted on any router.
+// Requests are traced; if metricsSrv is not nil, request metrics are recorded to it.
+func newJSONRPCHandler(cfg *HTTPServerConfig, metricsSrv *metrics.MetricsServer) (*MEVJSONRPCServer, http.Handler, error) {
+	mevServer := &MEVJSONRPCServer{
+		dbService: cfg.DBService,
+		log:       cfg.Log,
+	}
+	// the methods supported by this RPC server
+	methods := map[string]any{
+		RPCModuleByBlock:      mevServer.handleByBlock,
+		RPCModuleByTX:         mevServer.handleByTx,
+		RPCModuleMinerStats:   mevServer.handleMinerStats,
+		RPCModuleSandwiches:   mevServer.handleSandwiches,
+		RPCModuleLiquidations: mevServer.handleLiquidations,
+		RPCModuleBundle:       mevServer.handleBundle,
+	}
+	opts := rpcserver.JSONRPCHandlerOpts{}
+	handler, err := rpcserver.NewJSONRPCHandler(methods, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed creating JSONRPCHandler: %w", err)
+	}
+	return mevServer, newRPCInstrumentation(metricsSrv, methods).middleware(rpcErrorCodes(handler)), nil
+}
+
+// handleByTx() validates and normalizes the hash, then calls the DB Service with the appropriate method
+func (s *MEVJSONRPCServer) handleByTx(ctx context.Context, tx string, opts *ResponseOpts) (*database.MEVTransaction, error) {
 	s.log.Debug("MEVJSONRPCServer handleByTx", "tx", tx)
-	return s.dbService.GetMEVTx(tx)
+	hash, err := database.ParseHash(tx)
+	if err != nil {
+		return nil, s.toRPCError(ctx, err, MsgTxNotFound)
+	}
+	mevTx, err := s.dbService.GetMEVTx(ctx, string(hash))
+	if err != nil {
+		return nil, s.toRPCError(ctx, err, MsgTxNotFound)
+	}
+	if opts != nil && opts.Checksum {
+		return mevTx.Checksummed(), nil
+	}
+	return mevTx, nil
 }
 
-// handleByBlock() is simple, just calls the DB Service with the appropriate method
-func (s *MEVJSONRPCServer) handleByBlock(ctx context.Context, block string) (*database.MEVBlock, error) {
+// handleByBlock() validates and normalizes the block number or hash, then calls the DB Service with the appropriate method
+func (s *MEVJSONRPCServer) handleByBlock(ctx context.Context, block string, opts *ResponseOpts) (*database.MEVBlock, error) {
 	s.log.Debug("MEVJSONRPCServer handleByBlock", "block", block)
-	return s.dbService.GetMEVBlock(block)
+	isHash, _, err := database.ParseBlockID(block)
+	if err != nil {
+		return nil, s.toRPCError(ctx, err, MsgBlockNotFound)
+	}
+	if isHash {
+		block = string(common.NormalizeHash(block))
+	}
+	mevBlock, err := s.dbService.GetMEVBlock(ctx, block)
+	if err != nil {
+		return nil, s.toRPCError(ctx, err, MsgBlockNotFound)
+	}
+	if opts != nil && opts.Checksum {
+		return mevBlock.Checksummed(), nil
+	}
+	return mevBlock, nil
+}
+
+// handleBundle() returns the bundle containing a tx. A bundle has no addresses, only tx hashes,
+// so opts.Checksum doesn't change it; it is accepted like by the other methods.
+func (s *MEVJSONRPCServer) handleBundle(ctx context.Context, tx string, _ *ResponseOpts) (*database.Bundle, error) {
+	s.log.Debug("MEVJSONRPCServer handleBundle", "tx", tx)
+	hash, err := database.ParseHash(tx)
+	if err != nil {
+		return nil, s.toRPCError(ctx, err, MsgBundleNotFound)
+	}
+	bundle, err := s.dbService.GetBundle(ctx, string(hash))
+	if err != nil {
+		return nil, s.toRPCError(ctx, err, MsgBundleNotFound)
+	}
+	return bundle, nil
+}
+
+// handleMinerStats() returns per miner aggregates over an optional block and/or time window
+func (s *MEVJSONRPCServer) handleMinerStats(
+	ctx context.Context,
+	window *database.StatsWindow,
+	opts *ResponseOpts,
+) ([]*database.MinerStats, error) {
+	s.log.Debug("MEVJSONRPCServer handleMinerStats", "window", window)
+	if window == nil {
+		window = &database.StatsWindow{}
+	}
+	if (window.ToBlock > 0 && window.FromBlock > window.ToBlock) ||
+		(window.ToTime > 0 && window.FromTime > window.ToTime) {
+		return nil, s.toRPCError(ctx, ErrInvalidWindow, "")
+	}
+	stats, err := s.dbService.GetMinerStats(ctx, window)
+	if err != nil {
+		return nil, s.toRPCError(ctx, err, "")
+	}
+	if opts != nil && opts.Checksum {
+		return checksummed(stats), nil
+	}
+	return stats, nil
+}
+
+// handleSandwiches() returns the detected sandwiches matching an optional filter, the latest first
+func (s *MEVJSONRPCServer) handleSandwiches(
+	ctx context.Context,
+	filter *database.SandwichFilter,
+	opts *ResponseOpts,
+) ([]*database.Sandwich, error) {
+	s.log.Debug("MEVJSONRPCServer handleSandwiches", "filter", filter)
+	if filter == nil {
+		filter = &database.SandwichFilter{}
+	}
+	if filter.ToBlock > 0 && filter.FromBlock > filter.ToBlock {
+		return nil, s.toRPCError(ctx, ErrInvalidWindow, "")
+	}
+	sandwiches, err := s.dbService.GetSandwiches(ctx, filter)
+	if err != nil {
+		return nil, s.toRPCError(ctx, err, "")
+	}
+	if opts != nil && opts.Checksum {
+		return checksummed(sandwiches), nil
+	}
+	return sandwiches, nil
+}
+
+// handleLiquidations() returns the detected liquidations matching an optional filter, the latest first
+func (s *MEVJSONRPCServer) handleLiquidations(
+	ctx context.Context,
+	filter *database.LiquidationFilter,
+	opts *ResponseOpts,
+) ([]*database.Liquidation, error) {
+	s.log.Debug("MEVJSONRPCServer handleLiquidations", "filter", filter)
+	if filter == nil {
+		filter = &database.LiquidationFilter{}
+	}
+	if filter.ToBlock > 0 && filter.FromBlock > filter.ToBlock {
+		return nil, s.toRPCError(ctx, ErrInvalidWindow, "")
+	}
+	liquidations, err := s.dbService.GetLiquidations(ctx, filter)
+	if err != nil {
+		return nil, s.toRPCError(ctx, err, "")
+	}
+	if opts != nil && opts.Checksum {
+		return checksummed(liquidations), nil
+	}
+	return liquidations, nil
+}
+
+// checksummed returns copies of results with checksummed addresses
+func checksummed[T interface{ Checksummed() T }](results []T) []T {
+	out := make([]T, 0, len(results))
+	for _, r := range results {
+		out = append(out, r.Checksummed())
+	}
+	return out
 }
@@ -3,19 +3,29 @@ package httpserver
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/holisticode/mev-rpc/common"
 	"github.com/holisticode/mev-rpc/database"
 	"github.com/holisticode/mev-rpc/mocks"
 	"github.com/stretchr/testify/require"
 )
 
+const (
+	testTxHash1   = "0xb5c8bd9430b6cc87a0e2fe110ece6bf527fa4f170a4bc8cd032f768fc5a5bb50"
+	testTxHash2   = "0xb5c8bd9430b6cc87a0e2fe11aaaaaaaaaaaaaaaaaa4bc8cd032f768fc5a5bb50"
+	testBlockHash = "0x4c2707c769754fe23764a765fc8d50a5fa4172a670e46671dd3e1c0c34036dfe"
+	missingHash   = "0x0000000000000000000000000000000000000000000000000000000000000042"
+)
+
 // TestRPCServerReady() tests server readiness
 func TestRPCServerReady(t *testing.T) {
 	srv, err := New(&HTTPServerConfig{
@@ -32,6 +42,35 @@ func TestRPCServerReady(t *testing.T) {
 	}
 }
 
+// TestRPCServedByRouter() tests that the JSON-RPC handler is reachable through the main router
+func TestRPCServedByRouter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	srv, err := New(&HTTPServerConfig{
+		DBService: mockStorage,
+		Log:       getTestLogger(),
+	})
+	require.NoError(t, err)
+
+	tx := createMEVTx(testTxHash1)
+	mockStorage.EXPECT().GetMEVTx(gomock.Any(), testTxHash1).Return(tx, nil)
+
+	jsonReq := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": ["%s"]}`, RPCModuleByTX, testTxHash1)
+	req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(jsonReq)))
+	require.NoError(t, err)
+	req.Header.Add("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	srv.getRouter().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	var control database.MEVTransaction
+	require.NoError(t, json.Unmarshal(resp["result"], &control))
+	require.Equal(t, tx, &control)
+}
+
 // TestRPCEndpoints() tests our RPC endpoints by mocking the storage,
 // "saving" some data and then querying it via the RPC endpoints
 func TestRPCEndpoints(t *testing.T) {
@@ -46,8 +85,8 @@ func TestRPCEndpoints(t *testing.T) {
 	})
 	require.NoError(t, err)
 	// create some fictitious txs
-	tx1 := createMEVTx("0x1234")
-	tx2 := createMEVTx("0x4321")
+	tx1 := createMEVTx(testTxHash1)
+	tx2 := createMEVTx(testTxHash2)
 	// and the associated block
 	block := createMEVBlock()
 	block.MEVTransactions = []*database.MEVTransaction{tx1, tx2}
@@ -55,10 +94,10 @@ func TestRPCEndpoints(t *testing.T) {
 	strNum := strconv.FormatUint(block.BlockNumber, 10)
 
 	// storage mock sequence
-	s1 := mockStorage.EXPECT().GetMEVBlock("0x42").Return(nil, nil)
-	s2 := mockStorage.EXPECT().GetMEVBlock(strNum).After(s1).Return(block, nil)
-	s3 := mockStorage.EXPECT().GetMEVTx("0x4444").After(s2).Return(nil, nil)
-	mockStorage.EXPECT().GetMEVTx("0x1234").After(s3).Return(tx1, nil)
+	s1 := mockStorage.EXPECT().GetMEVBlock(gomock.Any(), missingHash).Return(nil, database.ErrNotFound)
+	s2 := mockStorage.EXPECT().GetMEVBlock(gomock.Any(), strNum).After(s1).Return(block, nil)
+	s3 := mockStorage.EXPECT().GetMEVTx(gomock.Any(), missingHash).After(s2).Return(nil, database.ErrNotFound)
+	mockStorage.EXPECT().GetMEVTx(gomock.Any(), testTxHash1).After(s3).Return(tx1, nil)
 
 	// TODO: The following test sequence could and probably should be refactored
 	// (better reuse and grouping)
@@ -71,27 +110,21 @@ func TestRPCEndpoints(t *testing.T) {
             "params": ["%s"
             ]
         }`
-		jsonReq1 := fmt.Sprintf(jsonReq, RPCModuleByBlock, "0x42")
+		jsonReq1 := fmt.Sprintf(jsonReq, RPCModuleByBlock, missingHash)
 		reader := bytes.NewReader([]byte(jsonReq1))
 		req, err := http.NewRequest(http.MethodPost, "/", reader) //nolint:goconst,nolintlint
 		req.Header.Add("Content-Type", "application/json")
 		require.NoError(t, err)
 		rr := httptest.NewRecorder()
 		srv.Handler.ServeHTTP(rr, req)
-		var control, empty database.MEVBlock
+		var control database.MEVBlock
 		var resp map[string]json.RawMessage
 		err = json.Unmarshal(rr.Body.Bytes(), &resp)
 		require.NoError(t, err)
-		respReader := bytes.NewReader(resp["result"])
-		// when the RPC server returns an error, it justs sets the "result" field to null
-		// a way to "catch" this as an error is to unmarshal that, it should get an empty object
-		err = json.NewDecoder(respReader).Decode(&control)
-		require.NoError(t, err)
-		// it seems to still return a StatusOK code, presumably because it is
-		// signaling that the call actually succeeded
+		// it still returns a StatusOK code, as the error is signaled in the JSON-RPC response
 		require.Equal(t, http.StatusOK, rr.Code)
-		// we should get an empty object
-		require.Equal(t, &empty, &control)
+		// we should get a not found error
+		requireRPCError(t, resp, CodeResourceNotFound, MsgBlockNotFound)
 
 		// 2nd request: this block exists
 		jsonReq2 := fmt.Sprintf(jsonReq, RPCModuleByBlock, strNum)
@@ -103,7 +136,7 @@ func TestRPCEndpoints(t *testing.T) {
 		srv.Handler.ServeHTTP(rr, req)
 		err = json.Unmarshal(rr.Body.Bytes(), &resp)
 		require.NoError(t, err)
-		respReader = bytes.NewReader(resp["result"])
+		respReader := bytes.NewReader(resp["result"])
 		err = json.NewDecoder(respReader).Decode(&control)
 		require.NoError(t, err)
 		require.Equal(t, http.StatusOK, rr.Code)
@@ -111,25 +144,22 @@ func TestRPCEndpoints(t *testing.T) {
 		require.Equal(t, block, &control)
 
 		// 3rd request: non-existent tx
-		jsonReq3 := fmt.Sprintf(jsonReq, RPCModuleByTX, "0x4444")
+		jsonReq3 := fmt.Sprintf(jsonReq, RPCModuleByTX, missingHash)
 		reader = bytes.NewReader([]byte(jsonReq3))
 		req, err = http.NewRequest(http.MethodPost, "/", reader) //nolint:goconst,nolintlint
 		req.Header.Add("Content-Type", "application/json")
 		require.NoError(t, err)
 		rr = httptest.NewRecorder()
 		srv.Handler.ServeHTTP(rr, req)
+		resp = nil
 		err = json.Unmarshal(rr.Body.Bytes(), &resp)
 		require.NoError(t, err)
-		respReader = bytes.NewReader(resp["result"])
-		var emptyTx, controlTx database.MEVTransaction
-		err = json.NewDecoder(respReader).Decode(&controlTx)
-		require.NoError(t, err)
 		require.Equal(t, http.StatusOK, rr.Code)
-		// same here, result is set to "null", and unmasrhalling should yield an empty object
-		require.Equal(t, &emptyTx, &controlTx)
+		// same here, we should get a not found error
+		requireRPCError(t, resp, CodeResourceNotFound, MsgTxNotFound)
 
 		// 4th request: this tx exists
-		jsonReq4 := fmt.Sprintf(jsonReq, RPCModuleByTX, "0x1234")
+		jsonReq4 := fmt.Sprintf(jsonReq, RPCModuleByTX, testTxHash1)
 		reader = bytes.NewReader([]byte(jsonReq4))
 		req, err = http.NewRequest(http.MethodPost, "/", reader) //nolint:goconst,nolintlint
 		req.Header.Add("Content-Type", "application/json")
@@ -138,6 +168,7 @@ func TestRPCEndpoints(t *testing.T) {
 		srv.Handler.ServeHTTP(rr, req)
 		err = json.Unmarshal(rr.Body.Bytes(), &resp)
 		require.NoError(t, err)
+		var controlTx database.MEVTransaction
 		respReader = bytes.NewReader(resp["result"])
 		err = json.NewDecoder(respReader).Decode(&controlTx)
 		require.NoError(t, err)
@@ -147,10 +178,103 @@ func TestRPCEndpoints(t *testing.T) {
 	}
 }
 
+// TestRPCErrorCodes() tests that errors are mapped to distinct JSON-RPC codes and stable messages
+func TestRPCErrorCodes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	srv, err := NewJSONRPCServer(&HTTPServerConfig{
+		DBService: mockStorage,
+		Log:       getTestLogger(),
+	})
+	require.NoError(t, err)
+
+	// invalid params never reach the storage
+	mockStorage.EXPECT().GetMEVTx(gomock.Any(), testTxHash1).Return(nil, fmt.Errorf("%w: connection refused", database.ErrUnavailable))
+	mockStorage.EXPECT().GetMEVTx(gomock.Any(), testTxHash2).Return(nil, errors.New("pq: relation does not exist"))
+
+	tests := []struct {
+		name    string
+		method  string
+		param   string
+		code    int
+		message string
+	}{
+		{"short hash", RPCModuleByTX, "0x1234", CodeInvalidParams, ""},
+		{"hash without prefix", RPCModuleByTX, testTxHash1[2:], CodeInvalidParams, ""},
+		{"invalid block number", RPCModuleByBlock, "-1", CodeInvalidParams, ""},
+		{"invalid block hash", RPCModuleByBlock, "0xzz", CodeInvalidParams, ""},
+		{"storage unavailable", RPCModuleByTX, testTxHash1, CodeResourceUnavailable, MsgUnavailable},
+		{"internal error", RPCModuleByTX, testTxHash2, CodeInternalError, MsgInternalError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonReq := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": ["%s"]}`, tt.method, tt.param)
+			req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(jsonReq)))
+			require.NoError(t, err)
+			req.Header.Add("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			srv.Handler.ServeHTTP(rr, req)
+			require.Equal(t, http.StatusOK, rr.Code)
+			var resp map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			requireRPCError(t, resp, tt.code, tt.message)
+		})
+	}
+}
+
+// TestRPCUndecodableParams() tests that params which can't be decoded are reported as invalid params
+func TestRPCUndecodableParams(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	// undecodable params never reach the storage
+	srv, err := NewJSONRPCServer(&HTTPServerConfig{
+		DBService: mocks.NewMockMEVTraceStorage(ctrl),
+		Log:       getTestLogger(),
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		method string
+		params string
+	}{
+		{"number instead of string", RPCModuleByBlock, `42`},
+		{"malformed window", RPCModuleMinerStats, `{"fromBlock": "latest"}`},
+		{"too many params", RPCModuleBundle, `"` + testTxHash1 + `", "` + testTxHash2 + `"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonReq := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": [%s]}`, tt.method, tt.params)
+			req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(jsonReq)))
+			require.NoError(t, err)
+			req.Header.Add("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			srv.Handler.ServeHTTP(rr, req)
+			require.Equal(t, http.StatusOK, rr.Code)
+			var resp map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			requireRPCError(t, resp, CodeInvalidParams, MsgInvalidParams)
+		})
+	}
+}
+
+// requireRPCError() checks the error of a JSON-RPC response; an empty message is not checked
+func requireRPCError(t *testing.T, resp map[string]json.RawMessage, code int, message string) {
+	t.Helper()
+	require.NotContains(t, resp, "result")
+	var rpcErr rpcError
+	require.NoError(t, json.Unmarshal(resp["error"], &rpcErr))
+	require.Equal(t, code, rpcErr.Code)
+	if message != "" {
+		require.Equal(t, message, rpcErr.Message)
+	}
+}
+
 func createMEVBlock() *database.MEVBlock {
 	return &database.MEVBlock{
 		BlockNumber:     21_000_042,
-		BlockHash:       "0x1234",
+		BlockHash:       testBlockHash,
 		Miner:           "0x8888",
 		IsFlashbotMiner: true,
 		TotalMinerValue: big.NewInt(4242),
@@ -160,9 +284,336 @@ func createMEVBlock() *database.MEVBlock {
 func createMEVTx(txHash string) *database.MEVTransaction {
 	return &database.MEVTransaction{
 		BlockNumber: 21_000_042,
-		TXHash:      txHash,
+		TXHash:      common.Hash(txHash),
 		From:        "0x1234",
 		To:          "0x4321",
 		Value:       big.NewInt(42),
 	}
 }
+
+// TestRPCMinerStats() tests the miner stats endpoint, including window validation
+func TestRPCMinerStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	srv, err := NewJSONRPCServer(&HTTPServerConfig{
+		DBService: mockStorage,
+		Log:       getTestLogger(),
+	})
+	require.NoError(t, err)
+
+	stats := []*database.MinerStats{
+		{
+			Miner:       "0x8888",
+			Blocks:      2,
+			Transfers:   3,
+			TotalValue:  big.NewInt(300),
+			MedianValue: big.NewInt(100),
+			MaxValue:    big.NewInt(200),
+			Share:       0.75,
+		},
+	}
+	window := &database.StatsWindow{FromBlock: 21_000_000, ToBlock: 21_000_100}
+	mockStorage.EXPECT().GetMinerStats(gomock.Any(), window).Return(stats, nil)
+
+	jsonReq := `{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": [%s]}`
+	doRequest := func(params string) map[string]json.RawMessage {
+		reader := bytes.NewReader([]byte(fmt.Sprintf(jsonReq, RPCModuleMinerStats, params)))
+		req, err := http.NewRequest(http.MethodPost, "/", reader)
+		require.NoError(t, err)
+		req.Header.Add("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		return resp
+	}
+
+	{ // valid window
+		resp := doRequest(`{"fromBlock": 21000000, "toBlock": 21000100}`)
+		var control []*database.MinerStats
+		require.NoError(t, json.Unmarshal(resp["result"], &control))
+		require.Equal(t, stats, control)
+	}
+
+	{ // inverted window never reaches the storage
+		resp := doRequest(`{"fromBlock": 21000100, "toBlock": 21000000}`)
+		requireRPCError(t, resp, CodeInvalidParams, ErrInvalidWindow.Error())
+	}
+}
+
+// TestRPCSandwiches() tests the sandwiches endpoint, including filter validation
+func TestRPCSandwiches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	srv, err := NewJSONRPCServer(&HTTPServerConfig{
+		DBService: mockStorage,
+		Log:       getTestLogger(),
+	})
+	require.NoError(t, err)
+
+	sandwiches := []*database.Sandwich{
+		{
+			BlockNumber: 21_000_042,
+			Attacker:    "0xae2fc483527b8ef99eb5d9b44875f005ba1fae13",
+			Pool:        "0x88e6a0c2ddd26feeb64f039a2c41296fcb3f5640",
+			Protocol:    "uniswap_v3",
+			FrontRunTx:  "0x01",
+			BackRunTx:   "0x03",
+			VictimTxs:   []common.Hash{"0x02"},
+			Victims:     []common.Address{"0x1234"},
+			ProfitToken: "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2",
+			Profit:      big.NewInt(4242),
+		},
+	}
+	filter := &database.SandwichFilter{FromBlock: 21_000_000, Attacker: "0xae2fc483527b8ef99eb5d9b44875f005ba1fae13"}
+	mockStorage.EXPECT().GetSandwiches(gomock.Any(), filter).Return(sandwiches, nil)
+	mockStorage.EXPECT().GetSandwiches(gomock.Any(), &database.SandwichFilter{Limit: 5000}).
+		Return(nil, fmt.Errorf("%w: limit too high", database.ErrInvalidInput))
+
+	jsonReq := `{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": [%s]}`
+	doRequest := func(params string) map[string]json.RawMessage {
+		reader := bytes.NewReader([]byte(fmt.Sprintf(jsonReq, RPCModuleSandwiches, params)))
+		req, err := http.NewRequest(http.MethodPost, "/", reader)
+		require.NoError(t, err)
+		req.Header.Add("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		return resp
+	}
+
+	{ // valid filter
+		resp := doRequest(`{"fromBlock": 21000000, "attacker": "0xae2fc483527b8ef99eb5d9b44875f005ba1fae13"}`)
+		var control []*database.Sandwich
+		require.NoError(t, json.Unmarshal(resp["result"], &control))
+		require.Equal(t, sandwiches, control)
+	}
+
+	{ // inverted window never reaches the storage
+		resp := doRequest(`{"fromBlock": 21000100, "toBlock": 21000000}`)
+		requireRPCError(t, resp, CodeInvalidParams, ErrInvalidWindow.Error())
+	}
+
+	{ // invalid input from the storage
+		resp := doRequest(`{"limit": 5000}`)
+		requireRPCError(t, resp, CodeInvalidParams, "")
+	}
+}
+
+// TestRPCLiquidations() tests the liquidations endpoint
+func TestRPCLiquidations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	srv, err := NewJSONRPCServer(&HTTPServerConfig{
+		DBService: mockStorage,
+		Log:       getTestLogger(),
+	})
+	require.NoError(t, err)
+
+	liquidations := []*database.Liquidation{
+		{
+			BlockNumber:      21_000_042,
+			TxHash:           "0x01",
+			Position:         3,
+			Protocol:         "aave",
+			Liquidator:       "0x2222222222222222222222222222222222222222",
+			Borrower:         "0x1111111111111111111111111111111111111111",
+			CollateralAsset:  "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2",
+			SeizedCollateral: big.NewInt(3),
+			DebtAsset:        "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48",
+			RepaidDebt:       big.NewInt(5000),
+			Bribe:            big.NewInt(42),
+		},
+	}
+	mockStorage.EXPECT().GetLiquidations(gomock.Any(), &database.LiquidationFilter{Protocol: "aave"}).Return(liquidations, nil)
+
+	jsonReq := `{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": [%s]}`
+	doRequest := func(params string) map[string]json.RawMessage {
+		reader := bytes.NewReader([]byte(fmt.Sprintf(jsonReq, RPCModuleLiquidations, params)))
+		req, err := http.NewRequest(http.MethodPost, "/", reader)
+		require.NoError(t, err)
+		req.Header.Add("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		return resp
+	}
+
+	{ // valid filter
+		resp := doRequest(`{"protocol": "aave"}`)
+		var control []*database.Liquidation
+		require.NoError(t, json.Unmarshal(resp["result"], &control))
+		require.Equal(t, liquidations, control)
+	}
+
+	{ // inverted window never reaches the storage
+		resp := doRequest(`{"fromBlock": 21000100, "toBlock": 21000000}`)
+		requireRPCError(t, resp, CodeInvalidParams, ErrInvalidWindow.Error())
+	}
+}
+
+// TestRPCBundle() tests the bundle endpoint, including a tx which is not part of a bundle
+func TestRPCBundle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	srv, err := NewJSONRPCServer(&HTTPServerConfig{
+		DBService: mockStorage,
+		Log:       getTestLogger(),
+	})
+	require.NoError(t, err)
+
+	bundleTx := "0x" + strings.Repeat("1", 64)
+	otherTx := "0x" + strings.Repeat("2", 64)
+	bundle := &database.Bundle{
+		BlockNumber: 21_000_042,
+		Position:    1,
+		TxHashes:    []common.Hash{common.Hash(bundleTx), common.Hash("0x" + strings.Repeat("3", 64))},
+		Payment:     big.NewInt(42),
+	}
+	mockStorage.EXPECT().GetBundle(gomock.Any(), bundleTx).Return(bundle, nil)
+	mockStorage.EXPECT().GetBundle(gomock.Any(), otherTx).Return(nil, database.ErrNotFound)
+
+	jsonReq := `{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": ["%s"]}`
+	doRequest := func(param string) map[string]json.RawMessage {
+		reader := bytes.NewReader([]byte(fmt.Sprintf(jsonReq, RPCModuleBundle, param)))
+		req, err := http.NewRequest(http.MethodPost, "/", reader)
+		require.NoError(t, err)
+		req.Header.Add("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		return resp
+	}
+
+	{ // found
+		resp := doRequest(bundleTx)
+		var control *database.Bundle
+		require.NoError(t, json.Unmarshal(resp["result"], &control))
+		require.Equal(t, bundle, control)
+	}
+
+	{ // not part of a bundle
+		resp := doRequest(otherTx)
+		requireRPCError(t, resp, CodeResourceNotFound, MsgBundleNotFound)
+	}
+
+	{ // malformed hash never reaches the storage
+		resp := doRequest("0x1234")
+		requireRPCError(t, resp, CodeInvalidParams, "")
+	}
+}
+
+// TestRPCNormalization() tests that hashes are looked up in lower case, and that addresses can be checksummed on every endpoint
+func TestRPCNormalization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStorage := mocks.NewMockMEVTraceStorage(ctrl)
+	srv, err := NewJSONRPCServer(&HTTPServerConfig{
+		DBService: mockStorage,
+		Log:       getTestLogger(),
+	})
+	require.NoError(t, err)
+
+	const (
+		lowerAddress    = "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+		checksumAddress = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	)
+	tx := createMEVTx(testTxHash1)
+	tx.From = lowerAddress
+	block := createMEVBlock()
+	block.Miner = lowerAddress
+	block.MEVTransactions = []*database.MEVTransaction{tx}
+	mockStorage.EXPECT().GetMEVTx(gomock.Any(), testTxHash1).Return(tx, nil).Times(2)
+	mockStorage.EXPECT().GetMEVBlock(gomock.Any(), testBlockHash).Return(block, nil)
+	sandwich := &database.Sandwich{Attacker: lowerAddress, Victims: []common.Address{lowerAddress}}
+	mockStorage.EXPECT().GetSandwiches(gomock.Any(), gomock.Any()).Return([]*database.Sandwich{sandwich}, nil)
+	liquidation := &database.Liquidation{Liquidator: lowerAddress, Borrower: lowerAddress}
+	mockStorage.EXPECT().GetLiquidations(gomock.Any(), gomock.Any()).Return([]*database.Liquidation{liquidation}, nil)
+	stats := &database.MinerStats{
+		Miner:       lowerAddress,
+		TokenTotals: []*database.TokenTotal{{Token: lowerAddress}},
+	}
+	mockStorage.EXPECT().GetMinerStats(gomock.Any(), gomock.Any()).Return([]*database.MinerStats{stats}, nil)
+	bundle := &database.Bundle{TxHashes: []common.Hash{testTxHash1}}
+	mockStorage.EXPECT().GetBundle(gomock.Any(), testTxHash1).Return(bundle, nil)
+
+	doRequest := func(method, params string) map[string]json.RawMessage {
+		jsonReq := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": %s}`, method, params)
+		req, err := http.NewRequest(http.MethodPost, "/", strings.NewReader(jsonReq))
+		require.NoError(t, err)
+		req.Header.Add("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		return resp
+	}
+	upper := func(hash string) string {
+		return "0x" + strings.ToUpper(hash[2:])
+	}
+
+	{ // upper case hash, lower case addresses by default
+		resp := doRequest(RPCModuleByTX, `["`+upper(testTxHash1)+`"]`)
+		var control map[string]any
+		require.NoError(t, json.Unmarshal(resp["result"], &control))
+		require.Equal(t, lowerAddress, control["from"])
+	}
+
+	{ // checksummed addresses on request
+		resp := doRequest(RPCModuleByTX, `["`+testTxHash1+`", {"checksum": true}]`)
+		var control map[string]any
+		require.NoError(t, json.Unmarshal(resp["result"], &control))
+		require.Equal(t, checksumAddress, control["from"])
+		// the stored tx is not modified
+		require.Equal(t, common.Address(lowerAddress), tx.From)
+	}
+
+	{ // blocks as well
+		resp := doRequest(RPCModuleByBlock, `["`+upper(testBlockHash)+`", {"checksum": true}]`)
+		var control map[string]any
+		require.NoError(t, json.Unmarshal(resp["result"], &control))
+		require.Equal(t, checksumAddress, control["miner"])
+		txs := control["transactions"].([]any)
+		require.Equal(t, checksumAddress, txs[0].(map[string]any)["from"])
+	}
+
+	{ // and the other endpoints
+		var sandwiches []map[string]any
+		resp := doRequest(RPCModuleSandwiches, `[{"fromBlock": 1, "toBlock": 2}, {"checksum": true}]`)
+		require.NoError(t, json.Unmarshal(resp["result"], &sandwiches))
+		require.Equal(t, checksumAddress, sandwiches[0]["attacker"])
+		require.Equal(t, []any{checksumAddress}, sandwiches[0]["victims"])
+		require.Equal(t, common.Address(lowerAddress), sandwich.Attacker)
+
+		var liquidations []map[string]any
+		resp = doRequest(RPCModuleLiquidations, `[{"fromBlock": 1, "toBlock": 2}, {"checksum": true}]`)
+		require.NoError(t, json.Unmarshal(resp["result"], &liquidations))
+		require.Equal(t, checksumAddress, liquidations[0]["liquidator"])
+		require.Equal(t, checksumAddress, liquidations[0]["borrower"])
+
+		var minerStats []map[string]any
+		resp = doRequest(RPCModuleMinerStats, `[{"fromBlock": 1, "toBlock": 2}, {"checksum": true}]`)
+		require.NoError(t, json.Unmarshal(resp["result"], &minerStats))
+		require.Equal(t, checksumAddress, minerStats[0]["miner"])
+		require.Equal(t, checksumAddress, minerStats[0]["tokenTotals"].([]any)[0].(map[string]any)["token"])
+		require.Equal(t, common.Address(lowerAddress), stats.TokenTotals[0].Token)
+
+		// bundles only hold hashes, which stay in lower case
+		var control map[string]any
+		resp = doRequest(RPCModuleBundle, `["`+upper(testTxHash1)+`", {"checksum": true}]`)
+		require.NoError(t, json.Unmarshal(resp["result"], &control))
+		require.Equal(t, []any{testTxHash1}, control["txHashes"])
+	}
+}
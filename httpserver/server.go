@@ -10,19 +10,48 @@ import (
 	"github.com/flashbots/go-utils/httplogger"
 	chi "github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/holisticode/mev-rpc/blocktrace"
 	"github.com/holisticode/mev-rpc/common"
 	"github.com/holisticode/mev-rpc/database"
 	"github.com/holisticode/mev-rpc/metrics"
 	"go.uber.org/atomic"
 )
 
+// DefaultMaxBlockLag is the number of blocks the tracer can lag behind the chain head
+// before the server reports as not ready, if not configured otherwise
+const DefaultMaxBlockLag = 10
+
+// SlotDuration is the time between two blocks, used to tell how many blocks old the stored data is
+const SlotDuration = 12 * time.Second
+
+// readinessCheckTimeout bounds the time spent on dependency checks in readiness probes
+const readinessCheckTimeout = 2 * time.Second
+
+// SyncStatusProvider reports how far the indexed data lags behind the chain head
+type SyncStatusProvider interface {
+	SyncStatus() blocktrace.SyncStatus
+}
+
 type HTTPServerConfig struct {
 	DBService database.MEVTraceStorage
+	// SyncStatus is used for readiness; it can be nil if no tracer is running in this process,
+	// in which case the age of the latest block stored in DBService is checked instead
+	SyncStatus SyncStatusProvider
+	// MaxBlockLag is the number of blocks the tracer, or else the stored data, can be behind before
+	// we report not ready; 0 requires it to be at the chain head. The CLI defaults it to DefaultMaxBlockLag.
+	MaxBlockLag uint64
+
+	// MetricsSrv can be shared with other components; it is created if nil
+	MetricsSrv *metrics.MetricsServer
 
 	ListenAddr  string
 	MetricsAddr string
+	// RPCPath is the path the JSON-RPC handler is mounted at (defaults to `/`)
+	RPCPath     string
 	EnablePprof bool
 	Log         *slog.Logger
+	// AdminToken enables the admin JSON-RPC methods at AdminRPCPath, for bearers of the token
+	AdminToken string
 
 	DrainDuration            time.Duration
 	GracefulShutdownDuration time.Duration
@@ -36,13 +65,19 @@ type Server struct {
 	log     *slog.Logger
 
 	srv        *http.Server
-	metricsSrv *metrics.MetricsServer
+	rpcHandler http.Handler
+	// adminHandler is nil if no admin token is configured
+	adminHandler http.Handler
+	metricsSrv   *metrics.MetricsServer
 }
 
 func New(cfg *HTTPServerConfig) (srv *Server, err error) {
-	metricsSrv, err := metrics.New(common.PackageName, cfg.MetricsAddr)
-	if err != nil {
-		return nil, err
+	metricsSrv := cfg.MetricsSrv
+	if metricsSrv == nil {
+		metricsSrv, err = metrics.New(common.PackageName, cfg.MetricsAddr)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	srv = &Server{
@@ -53,11 +88,26 @@ func New(cfg *HTTPServerConfig) (srv *Server, err error) {
 	}
 	srv.isReady.Swap(true)
 
-	rpcsrv, err := NewJSONRPCServer(cfg)
+	if cfg.RPCPath == "" {
+		cfg.RPCPath = "/"
+	}
+	_, rpcHandler, err := newJSONRPCHandler(cfg, metricsSrv)
 	if err != nil {
 		return nil, err
 	}
-	srv.srv = rpcsrv
+	srv.rpcHandler = rpcHandler
+	if cfg.AdminToken != "" {
+		if srv.adminHandler, err = newAdminRPCHandler(cfg, metricsSrv); err != nil {
+			return nil, err
+		}
+	}
+
+	srv.srv = &http.Server{
+		Addr:         cfg.ListenAddr,
+		Handler:      srv.getRouter(),
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+	}
 
 	return srv, nil
 }
@@ -113,6 +163,11 @@ func (srv *Server) getRouter() http.Handler {
 	mux.With(srv.httpLogger).Get("/readyz", srv.handleReadinessCheck)
 	mux.With(srv.httpLogger).Get("/drain", srv.handleDrain)
 	mux.With(srv.httpLogger).Get("/undrain", srv.handleUndrain)
+	mux.With(srv.httpLogger).Handle(srv.cfg.RPCPath, srv.rpcHandler)
+	if srv.adminHandler != nil {
+		srv.log.Info("admin API enabled", "path", AdminRPCPath)
+		mux.With(srv.httpLogger).Handle(AdminRPCPath, srv.adminHandler)
+	}
 
 	if srv.cfg.EnablePprof {
 		srv.log.Info("pprof API enabled")
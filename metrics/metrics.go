@@ -17,12 +17,25 @@ import (
 
 const (
 	UomMicroseconds = "us"
+	UomMilliseconds = "ms"
+	UomBlocks       = "{block}"
+	UomWei          = "wei"
 )
 
 var BucketsRequestDuration = []float64{
 	0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0, 30.0, 60.0,
 }
 
+// BucketsQueryDuration are buckets (in milliseconds) for queries served by our API
+var BucketsQueryDuration = []float64{
+	1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000,
+}
+
+// BucketsRPCDuration are buckets (in milliseconds) for outbound calls to a chain node
+var BucketsRPCDuration = []float64{
+	5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000,
+}
+
 type MetricsServer struct {
 	exporter *promotel.Exporter
 	meter    metric.Meter
@@ -33,6 +46,15 @@ type MetricsServer struct {
 
 	float64Histogram   map[string]metric.Float64Histogram
 	mxFloat64Histogram sync.RWMutex
+
+	int64Counter   map[string]metric.Int64Counter
+	mxInt64Counter sync.RWMutex
+
+	float64Counter   map[string]metric.Float64Counter
+	mxFloat64Counter sync.RWMutex
+
+	int64Gauge   map[string]metric.Int64ObservableGauge
+	mxInt64Gauge sync.RWMutex
 }
 
 func New(name, addr string) (metricsServer *MetricsServer, err error) {
@@ -66,6 +88,9 @@ func New(name, addr string) (metricsServer *MetricsServer, err error) {
 		server: server,
 
 		float64Histogram: make(map[string]metric.Float64Histogram),
+		int64Counter:     make(map[string]metric.Int64Counter),
+		float64Counter:   make(map[string]metric.Float64Counter),
+		int64Gauge:       make(map[string]metric.Int64ObservableGauge),
 	}
 
 	if err := runtime.Start(
@@ -81,6 +106,11 @@ func (ms *MetricsServer) ListenAndServe() error {
 	return ms.server.ListenAndServe()
 }
 
+// Handler returns the handler serving the metrics, e.g. to mount it on another router
+func (ms *MetricsServer) Handler() http.Handler {
+	return ms.server.Handler
+}
+
 // Shutdown gracefully shuts down the server without interrupting any
 // active connections.
 func (ms *MetricsServer) Shutdown(ctx context.Context) error {
@@ -105,31 +135,113 @@ func (ms *MetricsServer) Float64Histogram(
 	uom string,
 	bucketBounds ...float64,
 ) metric.Float64Histogram {
-	ms.mxFloat64Histogram.RLock()
-	if h, exists := ms.float64Histogram[name]; exists {
-		ms.mxFloat64Histogram.RUnlock()
-		return h
+	return getOrCreate(&ms.mxFloat64Histogram, ms.float64Histogram, name, func() (metric.Float64Histogram, error) {
+		return ms.meter.Float64Histogram(
+			name,
+			metric.WithDescription(description),
+			metric.WithExplicitBucketBoundaries(bucketBounds...),
+			metric.WithUnit(uom),
+		)
+	})
+}
+
+// Int64Counter returns an int64 counter with given name and parameters.
+//
+// Once created it's cached and reused further on, and it is thread-safe
+// (see Float64Histogram).
+//
+// See also: https://pkg.go.dev/go.opentelemetry.io/otel/metric@v1.21.0#Meter.Int64Counter
+//
+//nolint:ireturn,nolintlint
+func (ms *MetricsServer) Int64Counter(
+	name string,
+	description string,
+	uom string,
+) metric.Int64Counter {
+	return getOrCreate(&ms.mxInt64Counter, ms.int64Counter, name, func() (metric.Int64Counter, error) {
+		return ms.meter.Int64Counter(
+			name,
+			metric.WithDescription(description),
+			metric.WithUnit(uom),
+		)
+	})
+}
+
+// Float64Counter returns a float64 counter with given name and parameters.
+// It is useful for values which don't fit an int64, like amounts in wei.
+//
+// Once created it's cached and reused further on, and it is thread-safe
+// (see Float64Histogram).
+//
+// See also: https://pkg.go.dev/go.opentelemetry.io/otel/metric@v1.21.0#Meter.Float64Counter
+//
+//nolint:ireturn,nolintlint
+func (ms *MetricsServer) Float64Counter(
+	name string,
+	description string,
+	uom string,
+) metric.Float64Counter {
+	return getOrCreate(&ms.mxFloat64Counter, ms.float64Counter, name, func() (metric.Float64Counter, error) {
+		return ms.meter.Float64Counter(
+			name,
+			metric.WithDescription(description),
+			metric.WithUnit(uom),
+		)
+	})
+}
+
+// Int64Gauge registers an int64 gauge with given name and parameters.
+// The gauge's value is read by calling observe on every collection,
+// therefore observe must be thread-safe and return quickly.
+//
+// Only the first registration for a given name is effective; subsequent
+// calls with the same name return the already registered gauge.
+//
+// See also: https://pkg.go.dev/go.opentelemetry.io/otel/metric@v1.21.0#Meter.Int64ObservableGauge
+//
+//nolint:ireturn,nolintlint
+func (ms *MetricsServer) Int64Gauge(
+	name string,
+	description string,
+	uom string,
+	observe func() int64,
+) metric.Int64ObservableGauge {
+	return getOrCreate(&ms.mxInt64Gauge, ms.int64Gauge, name, func() (metric.Int64ObservableGauge, error) {
+		return ms.meter.Int64ObservableGauge(
+			name,
+			metric.WithDescription(description),
+			metric.WithUnit(uom),
+			metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+				o.Observe(observe())
+				return nil
+			}),
+		)
+	})
+}
+
+// getOrCreate returns the instrument with the given name from the cache,
+// or creates and caches it if it doesn't exist yet.
+func getOrCreate[T any](mx *sync.RWMutex, cache map[string]T, name string, create func() (T, error)) T {
+	mx.RLock()
+	if i, exists := cache[name]; exists {
+		mx.RUnlock()
+		return i
 	}
-	ms.mxFloat64Histogram.RUnlock()
+	mx.RUnlock()
 
-	ms.mxFloat64Histogram.Lock()
-	defer ms.mxFloat64Histogram.Unlock()
+	mx.Lock()
+	defer mx.Unlock()
 
 	// avoid race condition between ro-unlock and rw-lock
-	if h, exists := ms.float64Histogram[name]; exists {
-		return h
+	if i, exists := cache[name]; exists {
+		return i
 	}
 
-	h, err := ms.meter.Float64Histogram(
-		name,
-		metric.WithDescription(description),
-		metric.WithExplicitBucketBoundaries(bucketBounds...),
-		metric.WithUnit(uom),
-	)
+	i, err := create()
 	if err != nil {
 		panic(err)
 	}
 
-	ms.float64Histogram[name] = h
-	return h
+	cache[name] = i
+	return i
 }
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCountersAndGauges() tests that counters and gauges are cached and exported
+func TestCountersAndGauges(t *testing.T) {
+	ms, err := New("test", "")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// the same counter is returned for the same name
+	c := ms.Int64Counter("test_counter", "a test counter", "")
+	require.Equal(t, c, ms.Int64Counter("test_counter", "a test counter", ""))
+	c.Add(ctx, 2)
+	ms.Int64Counter("test_counter", "a test counter", "").Add(ctx, 3)
+
+	ms.Float64Counter("test_float_counter", "a test float counter", UomWei).Add(ctx, 1e20)
+
+	value := int64(42)
+	ms.Int64Gauge("test_gauge", "a test gauge", UomBlocks, func() int64 { return value })
+	// only the first registration is effective
+	ms.Int64Gauge("test_gauge", "a test gauge", UomBlocks, func() int64 { return 0 })
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rr := httptest.NewRecorder()
+	ms.Handler().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	body := rr.Body.String()
+	require.Contains(t, body, "test_counter_total")
+	require.Regexp(t, `test_counter_total\{[^}]*\} 5`, body)
+	require.Regexp(t, `test_float_counter_total\{[^}]*\} 1e\+20`, body)
+	require.Regexp(t, `test_gauge\{[^}]*\} 42`, body)
+}
@@ -5,7 +5,9 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	database "github.com/holisticode/mev-rpc/database"
@@ -34,75 +36,302 @@ func (m *MockMEVTraceStorage) EXPECT() *MockMEVTraceStorageMockRecorder {
 	return m.recorder
 }
 
+// DeleteLabel mocks base method.
+func (m *MockMEVTraceStorage) DeleteLabel(ctx context.Context, address string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLabel", ctx, address)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLabel indicates an expected call of DeleteLabel.
+func (mr *MockMEVTraceStorageMockRecorder) DeleteLabel(ctx, address interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLabel", reflect.TypeOf((*MockMEVTraceStorage)(nil).DeleteLabel), ctx, address)
+}
+
+// GetBundle mocks base method.
+func (m *MockMEVTraceStorage) GetBundle(ctx context.Context, txhash string) (*database.Bundle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBundle", ctx, txhash)
+	ret0, _ := ret[0].(*database.Bundle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBundle indicates an expected call of GetBundle.
+func (mr *MockMEVTraceStorageMockRecorder) GetBundle(ctx, txhash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBundle", reflect.TypeOf((*MockMEVTraceStorage)(nil).GetBundle), ctx, txhash)
+}
+
+// GetLabels mocks base method.
+func (m *MockMEVTraceStorage) GetLabels(ctx context.Context) ([]*database.Label, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLabels", ctx)
+	ret0, _ := ret[0].([]*database.Label)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLabels indicates an expected call of GetLabels.
+func (mr *MockMEVTraceStorageMockRecorder) GetLabels(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLabels", reflect.TypeOf((*MockMEVTraceStorage)(nil).GetLabels), ctx)
+}
+
+// GetLiquidations mocks base method.
+func (m *MockMEVTraceStorage) GetLiquidations(ctx context.Context, filter *database.LiquidationFilter) ([]*database.Liquidation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLiquidations", ctx, filter)
+	ret0, _ := ret[0].([]*database.Liquidation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLiquidations indicates an expected call of GetLiquidations.
+func (mr *MockMEVTraceStorageMockRecorder) GetLiquidations(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLiquidations", reflect.TypeOf((*MockMEVTraceStorage)(nil).GetLiquidations), ctx, filter)
+}
+
 // GetMEVBlock mocks base method.
-func (m *MockMEVTraceStorage) GetMEVBlock(block string) (*database.MEVBlock, error) {
+func (m *MockMEVTraceStorage) GetMEVBlock(ctx context.Context, block string) (*database.MEVBlock, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMEVBlock", block)
+	ret := m.ctrl.Call(m, "GetMEVBlock", ctx, block)
 	ret0, _ := ret[0].(*database.MEVBlock)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetMEVBlock indicates an expected call of GetMEVBlock.
-func (mr *MockMEVTraceStorageMockRecorder) GetMEVBlock(block interface{}) *gomock.Call {
+func (mr *MockMEVTraceStorageMockRecorder) GetMEVBlock(ctx, block interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMEVBlock", reflect.TypeOf((*MockMEVTraceStorage)(nil).GetMEVBlock), block)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMEVBlock", reflect.TypeOf((*MockMEVTraceStorage)(nil).GetMEVBlock), ctx, block)
 }
 
 // GetMEVTx mocks base method.
-func (m *MockMEVTraceStorage) GetMEVTx(tx string) (*database.MEVTransaction, error) {
+func (m *MockMEVTraceStorage) GetMEVTx(ctx context.Context, tx string) (*database.MEVTransaction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMEVTx", tx)
+	ret := m.ctrl.Call(m, "GetMEVTx", ctx, tx)
 	ret0, _ := ret[0].(*database.MEVTransaction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetMEVTx indicates an expected call of GetMEVTx.
-func (mr *MockMEVTraceStorageMockRecorder) GetMEVTx(tx interface{}) *gomock.Call {
+func (mr *MockMEVTraceStorageMockRecorder) GetMEVTx(ctx, tx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMEVTx", reflect.TypeOf((*MockMEVTraceStorage)(nil).GetMEVTx), tx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMEVTx", reflect.TypeOf((*MockMEVTraceStorage)(nil).GetMEVTx), ctx, tx)
+}
+
+// GetMinerStats mocks base method.
+func (m *MockMEVTraceStorage) GetMinerStats(ctx context.Context, window *database.StatsWindow) ([]*database.MinerStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMinerStats", ctx, window)
+	ret0, _ := ret[0].([]*database.MinerStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMinerStats indicates an expected call of GetMinerStats.
+func (mr *MockMEVTraceStorageMockRecorder) GetMinerStats(ctx, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMinerStats", reflect.TypeOf((*MockMEVTraceStorage)(nil).GetMinerStats), ctx, window)
+}
+
+// GetSandwiches mocks base method.
+func (m *MockMEVTraceStorage) GetSandwiches(ctx context.Context, filter *database.SandwichFilter) ([]*database.Sandwich, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSandwiches", ctx, filter)
+	ret0, _ := ret[0].([]*database.Sandwich)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSandwiches indicates an expected call of GetSandwiches.
+func (mr *MockMEVTraceStorageMockRecorder) GetSandwiches(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSandwiches", reflect.TypeOf((*MockMEVTraceStorage)(nil).GetSandwiches), ctx, filter)
 }
 
 // LatestBlock mocks base method.
-func (m *MockMEVTraceStorage) LatestBlock() (uint64, error) {
+func (m *MockMEVTraceStorage) LatestBlock(ctx context.Context) (uint64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LatestBlock")
+	ret := m.ctrl.Call(m, "LatestBlock", ctx)
 	ret0, _ := ret[0].(uint64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // LatestBlock indicates an expected call of LatestBlock.
-func (mr *MockMEVTraceStorageMockRecorder) LatestBlock() *gomock.Call {
+func (mr *MockMEVTraceStorageMockRecorder) LatestBlock(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestBlock", reflect.TypeOf((*MockMEVTraceStorage)(nil).LatestBlock))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestBlock", reflect.TypeOf((*MockMEVTraceStorage)(nil).LatestBlock), ctx)
+}
+
+// LatestBlockTime mocks base method.
+func (m *MockMEVTraceStorage) LatestBlockTime(ctx context.Context) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LatestBlockTime", ctx)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LatestBlockTime indicates an expected call of LatestBlockTime.
+func (mr *MockMEVTraceStorageMockRecorder) LatestBlockTime(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestBlockTime", reflect.TypeOf((*MockMEVTraceStorage)(nil).LatestBlockTime), ctx)
 }
 
 // OldestBlock mocks base method.
-func (m *MockMEVTraceStorage) OldestBlock() uint64 {
+func (m *MockMEVTraceStorage) OldestBlock(ctx context.Context) uint64 {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "OldestBlock")
+	ret := m.ctrl.Call(m, "OldestBlock", ctx)
 	ret0, _ := ret[0].(uint64)
 	return ret0
 }
 
 // OldestBlock indicates an expected call of OldestBlock.
-func (mr *MockMEVTraceStorageMockRecorder) OldestBlock() *gomock.Call {
+func (mr *MockMEVTraceStorageMockRecorder) OldestBlock(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OldestBlock", reflect.TypeOf((*MockMEVTraceStorage)(nil).OldestBlock))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OldestBlock", reflect.TypeOf((*MockMEVTraceStorage)(nil).OldestBlock), ctx)
+}
+
+// Ping mocks base method.
+func (m *MockMEVTraceStorage) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockMEVTraceStorageMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockMEVTraceStorage)(nil).Ping), ctx)
 }
 
 // SaveMEVBLock mocks base method.
-func (m *MockMEVTraceStorage) SaveMEVBLock(block *database.MEVBlock, txs []*database.MEVTransaction) error {
+func (m *MockMEVTraceStorage) SaveMEVBLock(ctx context.Context, block *database.MEVBlock, txs []*database.MEVTransaction) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveMEVBLock", block, txs)
+	ret := m.ctrl.Call(m, "SaveMEVBLock", ctx, block, txs)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SaveMEVBLock indicates an expected call of SaveMEVBLock.
-func (mr *MockMEVTraceStorageMockRecorder) SaveMEVBLock(block, txs interface{}) *gomock.Call {
+func (mr *MockMEVTraceStorageMockRecorder) SaveMEVBLock(ctx, block, txs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMEVBLock", reflect.TypeOf((*MockMEVTraceStorage)(nil).SaveMEVBLock), ctx, block, txs)
+}
+
+// SaveUSDValues mocks base method.
+func (m *MockMEVTraceStorage) SaveUSDValues(ctx context.Context, block *database.MEVBlock) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveUSDValues", ctx, block)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveUSDValues indicates an expected call of SaveUSDValues.
+func (mr *MockMEVTraceStorageMockRecorder) SaveUSDValues(ctx, block interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUSDValues", reflect.TypeOf((*MockMEVTraceStorage)(nil).SaveUSDValues), ctx, block)
+}
+
+// SetLabel mocks base method.
+func (m *MockMEVTraceStorage) SetLabel(ctx context.Context, label *database.Label) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLabel", ctx, label)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLabel indicates an expected call of SetLabel.
+func (mr *MockMEVTraceStorageMockRecorder) SetLabel(ctx, label interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLabel", reflect.TypeOf((*MockMEVTraceStorage)(nil).SetLabel), ctx, label)
+}
+
+// MockBackfillQueue is a mock of BackfillQueue interface.
+type MockBackfillQueue struct {
+	ctrl     *gomock.Controller
+	recorder *MockBackfillQueueMockRecorder
+}
+
+// MockBackfillQueueMockRecorder is the mock recorder for MockBackfillQueue.
+type MockBackfillQueueMockRecorder struct {
+	mock *MockBackfillQueue
+}
+
+// NewMockBackfillQueue creates a new mock instance.
+func NewMockBackfillQueue(ctrl *gomock.Controller) *MockBackfillQueue {
+	mock := &MockBackfillQueue{ctrl: ctrl}
+	mock.recorder = &MockBackfillQueueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBackfillQueue) EXPECT() *MockBackfillQueueMockRecorder {
+	return m.recorder
+}
+
+// ClaimBackfillShard mocks base method.
+func (m *MockBackfillQueue) ClaimBackfillShard(ctx context.Context, from, to uint64, worker string, lease time.Duration) (*database.BackfillShard, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimBackfillShard", ctx, from, to, worker, lease)
+	ret0, _ := ret[0].(*database.BackfillShard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimBackfillShard indicates an expected call of ClaimBackfillShard.
+func (mr *MockBackfillQueueMockRecorder) ClaimBackfillShard(ctx, from, to, worker, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimBackfillShard", reflect.TypeOf((*MockBackfillQueue)(nil).ClaimBackfillShard), ctx, from, to, worker, lease)
+}
+
+// CompleteBackfillShard mocks base method.
+func (m *MockBackfillQueue) CompleteBackfillShard(ctx context.Context, id uint64, worker string, failedBlocks uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteBackfillShard", ctx, id, worker, failedBlocks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteBackfillShard indicates an expected call of CompleteBackfillShard.
+func (mr *MockBackfillQueueMockRecorder) CompleteBackfillShard(ctx, id, worker, failedBlocks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteBackfillShard", reflect.TypeOf((*MockBackfillQueue)(nil).CompleteBackfillShard), ctx, id, worker, failedBlocks)
+}
+
+// CreateBackfillShards mocks base method.
+func (m *MockBackfillQueue) CreateBackfillShards(ctx context.Context, from, to, shardSize uint64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBackfillShards", ctx, from, to, shardSize)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBackfillShards indicates an expected call of CreateBackfillShards.
+func (mr *MockBackfillQueueMockRecorder) CreateBackfillShards(ctx, from, to, shardSize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBackfillShards", reflect.TypeOf((*MockBackfillQueue)(nil).CreateBackfillShards), ctx, from, to, shardSize)
+}
+
+// RenewBackfillLease mocks base method.
+func (m *MockBackfillQueue) RenewBackfillLease(ctx context.Context, id uint64, worker string, lease time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenewBackfillLease", ctx, id, worker, lease)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenewBackfillLease indicates an expected call of RenewBackfillLease.
+func (mr *MockBackfillQueueMockRecorder) RenewBackfillLease(ctx, id, worker, lease interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMEVBLock", reflect.TypeOf((*MockMEVTraceStorage)(nil).SaveMEVBLock), block, txs)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewBackfillLease", reflect.TypeOf((*MockBackfillQueue)(nil).RenewBackfillLease), ctx, id, worker, lease)
 }
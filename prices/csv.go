@@ -0,0 +1,115 @@
+package prices
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxAge is how long a price of the series is used for, e.g. a daily close for the whole day
+const DefaultMaxAge = 24 * time.Hour
+
+var ErrInvalidPrices = errors.New("invalid prices file")
+
+// CSVProvider serves prices from a historical series loaded in memory.
+// The price at a timestamp is the latest one of the series at or before it.
+type CSVProvider struct {
+	// MaxAge is how long after its timestamp a price is still used; there is no price after that
+	MaxAge time.Duration
+
+	times  []uint64
+	prices []float64
+}
+
+// LoadCSV reads a prices file, see ReadCSV
+func LoadCSV(path string) (*CSVProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadCSV(f)
+}
+
+// ReadCSV reads a series of `time,price` records, with an optional header row.
+// Times are unix timestamps in seconds, RFC 3339 times or dates (YYYY-MM-DD, UTC); the order doesn't matter.
+func ReadCSV(r io.Reader) (*CSVProvider, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+	type point struct {
+		time  uint64
+		price float64
+	}
+	var points []point
+	for line := 1; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPrices, err)
+		}
+		t, err := parseTime(record[0])
+		if err != nil {
+			if line == 1 {
+				// header
+				continue
+			}
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidPrices, line, err)
+		}
+		price, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil || price <= 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+			return nil, fmt.Errorf("%w: line %d: price must be a positive number", ErrInvalidPrices, line)
+		}
+		points = append(points, point{t, price})
+	}
+	if len(points) == 0 {
+		return nil, fmt.Errorf("%w: no prices", ErrInvalidPrices)
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].time < points[j].time })
+	p := &CSVProvider{MaxAge: DefaultMaxAge}
+	for i, pt := range points {
+		if i > 0 && pt.time == points[i-1].time {
+			return nil, fmt.Errorf("%w: duplicate time %d", ErrInvalidPrices, pt.time)
+		}
+		p.times = append(p.times, pt.time)
+		p.prices = append(p.prices, pt.price)
+	}
+	return p, nil
+}
+
+// parseTime parses a unix timestamp, an RFC 3339 time or a date
+func parseTime(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	if ts, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return ts, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil && t.Unix() >= 0 {
+			return uint64(t.Unix()), nil //nolint:gosec
+		}
+	}
+	return 0, fmt.Errorf("time must be a unix timestamp, an RFC 3339 time or a date: %q", s)
+}
+
+// ETHUSD returns the latest price at or before timestamp, if it's not older than MaxAge
+func (p *CSVProvider) ETHUSD(_ context.Context, timestamp uint64) (float64, error) {
+	// index of the first time after timestamp
+	i := sort.Search(len(p.times), func(i int) bool { return p.times[i] > timestamp })
+	if i == 0 {
+		return 0, fmt.Errorf("%w: %d is before the first price", ErrNoPrice, timestamp)
+	}
+	if age := timestamp - p.times[i-1]; p.MaxAge > 0 && age > uint64(p.MaxAge.Seconds()) {
+		return 0, fmt.Errorf("%w: the latest price before %d is too old", ErrNoPrice, timestamp)
+	}
+	return p.prices[i-1], nil
+}
@@ -0,0 +1,64 @@
+package prices
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCSV(t *testing.T) {
+	p, err := ReadCSV(strings.NewReader(`time,price
+2024-11-02,2500.5
+1730419200, 2450
+2024-11-03T12:00:00Z,2600
+`))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		timestamp uint64
+		price     float64
+	}{
+		// 2024-11-01, the first price
+		{1730419200, 2450},
+		{1730419200 + 3600, 2450},
+		// 2024-11-02
+		{1730505600, 2500.5},
+		{1730505600 + 12*3600, 2500.5},
+		// 2024-11-03 12:00 and after, for a day
+		{1730635200, 2600},
+		{1730635200 + 24*3600, 2600},
+	} {
+		price, err := p.ETHUSD(t.Context(), tc.timestamp)
+		require.NoError(t, err, tc.timestamp)
+		require.InDelta(t, tc.price, price, 1e-9, tc.timestamp)
+	}
+
+	// before the series, and too long after it
+	_, err = p.ETHUSD(t.Context(), 1730419200-1)
+	require.ErrorIs(t, err, ErrNoPrice)
+	_, err = p.ETHUSD(t.Context(), 1730635200+24*3600+1)
+	require.ErrorIs(t, err, ErrNoPrice)
+	p.MaxAge = 0
+	_, err = p.ETHUSD(t.Context(), 1730635200+24*3600+1)
+	require.NoError(t, err)
+	p.MaxAge = time.Hour
+	_, err = p.ETHUSD(t.Context(), 1730505600+3601)
+	require.ErrorIs(t, err, ErrNoPrice)
+}
+
+func TestReadCSVErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"empty":          "",
+		"header only":    "time,price\n",
+		"bad time":       "1730419200,2450\nyesterday,2500\n",
+		"bad price":      "1730419200,abc\n",
+		"negative price": "1730419200,-1\n",
+		"extra column":   "1730419200,2450,usd\n",
+		"duplicate time": "1730419200,2450\n2024-11-01,2500\n",
+	} {
+		_, err := ReadCSV(strings.NewReader(data))
+		require.ErrorIs(t, err, ErrInvalidPrices, name)
+	}
+}
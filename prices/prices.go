@@ -0,0 +1,30 @@
+// Package prices provides ETH/USD prices to value MEV in USD
+package prices
+
+import (
+	"context"
+	"errors"
+	"math/big"
+)
+
+// ErrNoPrice is returned if a provider has no price for the requested time
+var ErrNoPrice = errors.New("no price")
+
+// Provider returns the ETH/USD price at a point in time
+type Provider interface {
+	// ETHUSD returns the price at the unix timestamp, or ErrNoPrice
+	ETHUSD(ctx context.Context, timestamp uint64) (float64, error)
+}
+
+// weiPerETH is 10^18
+var weiPerETH = new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+
+// USDValue converts an amount in wei to USD at the given ETH/USD price
+func USDValue(wei *big.Int, price float64) float64 {
+	if wei == nil {
+		return 0
+	}
+	eth := new(big.Float).Quo(new(big.Float).SetInt(wei), weiPerETH)
+	usd, _ := eth.Mul(eth, big.NewFloat(price)).Float64()
+	return usd
+}
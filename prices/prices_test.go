@@ -0,0 +1,14 @@
+package prices
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUSDValue(t *testing.T) {
+	wei, _ := new(big.Int).SetString("1500000000000000000", 10)
+	require.InDelta(t, 3750.75, USDValue(wei, 2500.5), 1e-9)
+	require.InDelta(t, 0, USDValue(nil, 2500.5), 0)
+}
@@ -0,0 +1,4 @@
+// Package tracing sets up OpenTelemetry distributed tracing.
+//
+// Spans are exported via OTLP, or written to stdout / a file for local development.
+package tracing
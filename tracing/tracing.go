@@ -0,0 +1,136 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/holisticode/mev-rpc/common"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Supported exporters
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+var ErrUnknownExporter = errors.New("unknown tracing exporter")
+
+type Opts struct {
+	// Exporter is one of the Exporter* constants; tracing is disabled if empty or none
+	Exporter string
+	// OTLPEndpoint is the host:port of the OTLP HTTP collector.
+	// If empty, the OTEL_EXPORTER_OTLP_* env vars or the exporter's default are used.
+	OTLPEndpoint string
+	// OTLPInsecure disables TLS towards the collector
+	OTLPInsecure bool
+	// FilePath is where spans are written for the file exporter
+	FilePath string
+	// SampleRatio is the fraction of traces to be sampled (parent based)
+	SampleRatio float64
+
+	Service string
+	Version string
+}
+
+// Setup configures the global tracer provider and propagator.
+// The returned function flushes pending spans and must be called on shutdown.
+func Setup(ctx context.Context, opts *Opts) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var (
+		exporter sdktrace.SpanExporter
+		closer   io.Closer
+	)
+	switch opts.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		clientOpts := []otlptracehttp.Option{}
+		if opts.OTLPEndpoint != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpoint(opts.OTLPEndpoint))
+		}
+		if opts.OTLPInsecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, clientOpts...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterFile:
+		f, ferr := os.OpenFile(opts.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644) //nolint:gosec
+		if ferr != nil {
+			return nil, fmt.Errorf("failed to open tracing file: %w", ferr)
+		}
+		closer = f
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownExporter, opts.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(opts.Service),
+		semconv.ServiceVersion(opts.Version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}, nil
+}
+
+// Tracer returns the tracer used for all spans of this service
+//
+//nolint:ireturn
+func Tracer() trace.Tracer {
+	return otel.Tracer(common.PackageName)
+}
+
+// Start starts a span with the given attributes
+//
+//nolint:ireturn
+func Start(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+}
+
+// End records err on the span, if not nil, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,36 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestSetup() tests that spans are exported to a file and unknown exporters are rejected
+func TestSetup(t *testing.T) {
+	_, err := Setup(context.Background(), &Opts{Exporter: "zipkin"})
+	require.ErrorIs(t, err, ErrUnknownExporter)
+
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Setup(context.Background(), &Opts{
+		Exporter:    ExporterFile,
+		FilePath:    path,
+		SampleRatio: 1,
+		Service:     "test",
+	})
+	require.NoError(t, err)
+
+	_, span := Start(context.Background(), "test-span", trace.SpanKindInternal)
+	End(span, errors.New("failed")) //nolint:goerr113
+	require.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"Name":"test-span"`)
+	require.Contains(t, string(data), `"Description":"failed"`)
+}